@@ -32,24 +32,36 @@ func (c *Converter) Convert(i interface{}) interface{} {
 	case *models.User:
 		if c.ToProto {
 			return &pb.User{
-				UserId: v.UserID,
-				Name:   v.Name,
-				Status: v.Status,
+				UserId:         v.UserID,
+				Name:           v.Name,
+				Status:         v.Status,
+				Handle:         v.Handle,
+				DisplayName:    v.DisplayName,
+				CreatedAt:      timestamppb.New(v.CreatedAt),
+				FollowerCount:  int32(v.FollowerCount),
+				FollowingCount: int32(v.FollowingCount),
+				ActivityLevel:  v.ActivityLevel,
 			}
 		}
 	case *pb.User:
 		if !c.ToProto {
 			return &models.User{
-				UserID: v.UserId,
-				Name:   v.Name,
-				Status: v.Status,
+				UserID:         v.UserId,
+				Name:           v.Name,
+				Status:         v.Status,
+				Handle:         v.Handle,
+				DisplayName:    v.DisplayName,
+				CreatedAt:      v.CreatedAt.AsTime(),
+				FollowerCount:  int(v.FollowerCount),
+				FollowingCount: int(v.FollowingCount),
+				ActivityLevel:  v.ActivityLevel,
 			}
 		}
 
 	case models.Comment:
 		if c.ToProto {
 			return &pb.Comment{
-				User:     c.Convert(&v.User).(*pb.User),
+				User:     c.Convert(v.User).(*pb.User),
 				Content:  v.Content,
 				PostedAt: timestamppb.New(v.PostedAt),
 			}
@@ -66,7 +78,7 @@ func (c *Converter) Convert(i interface{}) interface{} {
 	case models.Reaction:
 		if c.ToProto {
 			return &pb.Reaction{
-				User:         c.Convert(&v.User).(*pb.User),
+				User:         c.Convert(v.User).(*pb.User),
 				ReactionType: v.ReactionType,
 				Count:        int32(v.Count),
 			}
@@ -95,6 +107,9 @@ func (c *Converter) Convert(i interface{}) interface{} {
 				User:      c.Convert(v.User).(*pb.User),
 				Message:   v.Message,
 				Hashtags:  v.HashTag,
+				Mentions:  v.Mentions,
+				Urls:      v.URLs,
+				Cashtags:  v.Cashtags,
 				Comments:  comments,
 				Reactions: reactions,
 				CreatedAt: timestamppb.New(v.CreatedAt),
@@ -117,13 +132,60 @@ func (c *Converter) Convert(i interface{}) interface{} {
 				User:      c.Convert(v.User).(*models.User),
 				Message:   v.Message,
 				HashTag:   v.Hashtags,
+				Mentions:  v.Mentions,
+				URLs:      v.Urls,
+				Cashtags:  v.Cashtags,
 				Comments:  comments,
 				Reactions: reactions,
 				CreatedAt: v.CreatedAt.AsTime(),
 				UpdatedAt: v.UpdatedAt.AsTime(),
 			}
 		}
+
+	case *models.TweetEvent:
+		if c.ToProto {
+			event := &pb.TweetEvent{
+				EventId:   v.EventID,
+				Type:      eventTypeToProto[v.Type],
+				Sequence:  v.Sequence,
+				Offset:    v.Offset,
+				Tweet:     c.Convert(v.Tweet).(*pb.Tweet),
+				EmittedAt: timestamppb.New(v.EmittedAt),
+			}
+			if v.Previous != nil {
+				event.Previous = c.Convert(v.Previous).(*pb.Tweet)
+			}
+			return event
+		}
+
+	case *pb.TweetEvent:
+		if !c.ToProto {
+			event := &models.TweetEvent{
+				EventID:   v.EventId,
+				Type:      eventTypeFromProto[v.Type],
+				Sequence:  v.Sequence,
+				Offset:    v.Offset,
+				Tweet:     c.Convert(v.Tweet).(*models.Tweet),
+				EmittedAt: v.EmittedAt.AsTime(),
+			}
+			if v.Previous != nil {
+				event.Previous = c.Convert(v.Previous).(*models.Tweet)
+			}
+			return event
+		}
 	}
 
 	return nil
 }
+
+var eventTypeToProto = map[models.EventType]pb.EventType{
+	models.EventCreated: pb.EventType_EVENT_TYPE_CREATED,
+	models.EventUpdated: pb.EventType_EVENT_TYPE_UPDATED,
+	models.EventDeleted: pb.EventType_EVENT_TYPE_DELETED,
+}
+
+var eventTypeFromProto = map[pb.EventType]models.EventType{
+	pb.EventType_EVENT_TYPE_CREATED: models.EventCreated,
+	pb.EventType_EVENT_TYPE_UPDATED: models.EventUpdated,
+	pb.EventType_EVENT_TYPE_DELETED: models.EventDeleted,
+}
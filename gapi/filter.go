@@ -0,0 +1,130 @@
+package gapi
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/Udehlee/tweet-stream/internals/entities"
+	"github.com/Udehlee/tweet-stream/models"
+	"github.com/Udehlee/tweet-stream/pb"
+)
+
+// Filter decides which events a stream subscriber receives
+// an empty filter matches everything
+type Filter struct {
+	hashtags     map[string]struct{}
+	userIDs      map[string]struct{}
+	verifiedOnly bool
+	keywords     []string
+	pattern      *regexp.Regexp
+	eventTypes   map[models.EventType]struct{}
+}
+
+// NewFilter builds a Filter from a stream request
+func NewFilter(req *pb.StreamRequest) (*Filter, error) {
+	f := &Filter{
+		hashtags:     make(map[string]struct{}),
+		userIDs:      make(map[string]struct{}),
+		verifiedOnly: req.GetVerifiedOnly(),
+		eventTypes:   make(map[models.EventType]struct{}),
+	}
+
+	for _, tag := range req.GetHashtags() {
+		if tag = entities.NormalizeHashtag(tag); tag != "" {
+			f.hashtags[tag] = struct{}{}
+		}
+	}
+
+	for _, id := range req.GetUserIds() {
+		if id = strings.TrimSpace(id); id != "" {
+			f.userIDs[id] = struct{}{}
+		}
+	}
+
+	for _, kw := range req.GetKeywords() {
+		if kw = strings.ToLower(strings.TrimSpace(kw)); kw != "" {
+			f.keywords = append(f.keywords, kw)
+		}
+	}
+
+	if expr := req.GetMessageRegex(); expr != "" {
+		pattern, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid message_regex: %w", err)
+		}
+		f.pattern = pattern
+	}
+
+	for _, t := range req.GetEventTypes() {
+		eventType, ok := eventTypeFromProto[t]
+		if !ok {
+			return nil, fmt.Errorf("unsupported event type %s", t)
+		}
+		f.eventTypes[eventType] = struct{}{}
+	}
+
+	return f, nil
+}
+
+// Match reports whether the event passes every configured filter
+func (f *Filter) Match(event *models.TweetEvent) bool {
+	if event == nil || event.Tweet == nil {
+		return false
+	}
+	tweet := event.Tweet
+
+	if len(f.eventTypes) > 0 {
+		if _, ok := f.eventTypes[event.Type]; !ok {
+			return false
+		}
+	}
+
+	if len(f.userIDs) > 0 {
+		if tweet.User == nil {
+			return false
+		}
+		if _, ok := f.userIDs[tweet.User.UserID]; !ok {
+			return false
+		}
+	}
+
+	if f.verifiedOnly && (tweet.User == nil || tweet.User.Status != "verified") {
+		return false
+	}
+
+	if len(f.hashtags) > 0 && !f.matchHashtags(tweet) {
+		return false
+	}
+
+	if len(f.keywords) > 0 && !f.matchKeywords(tweet.Message) {
+		return false
+	}
+
+	if f.pattern != nil && !f.pattern.MatchString(tweet.Message) {
+		return false
+	}
+
+	return true
+}
+
+// matchHashtags reports whether the tweet carries any of the wanted hashtags
+func (f *Filter) matchHashtags(tweet *models.Tweet) bool {
+	for _, tag := range tweet.HashTag {
+		if _, ok := f.hashtags[entities.NormalizeHashtag(tag)]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// matchKeywords reports whether the message contains any keyword, ignoring case
+func (f *Filter) matchKeywords(msg string) bool {
+	msg = strings.ToLower(msg)
+	for _, kw := range f.keywords {
+		if strings.Contains(msg, kw) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,90 @@
+package gapi
+
+import (
+	"container/list"
+	"context"
+	"sync"
+
+	pb "github.com/Udehlee/tweet-stream/pb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/proto"
+)
+
+// idempotencyCache remembers the ack of the most recent keys
+// a key is reserved before its write runs so a retry that arrives while the
+// first attempt is still running waits for it instead of applying twice
+type idempotencyCache struct {
+	mu       sync.Mutex
+	capacity int
+	entries  map[string]*list.Element
+	order    *list.List // oldest first
+}
+
+type idempotencyEntry struct {
+	key  string
+	ack  *pb.IngestAck
+	done chan struct{} // closed once ack is set
+}
+
+func newIdempotencyCache(capacity int) *idempotencyCache {
+	return &idempotencyCache{
+		capacity: capacity,
+		entries:  make(map[string]*list.Element),
+		order:    list.New(),
+	}
+}
+
+// reserve returns the entry of key and whether the caller owns it
+// the owner must call complete, everyone else waits on the entry
+func (c *idempotencyCache) reserve(key string) (*idempotencyEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.entries[key]; ok {
+		c.order.MoveToBack(elem)
+		return elem.Value.(*idempotencyEntry), false
+	}
+
+	entry := &idempotencyEntry{key: key, done: make(chan struct{})}
+	c.entries[key] = c.order.PushBack(entry)
+
+	for c.order.Len() > c.capacity {
+		oldest := c.order.Front()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*idempotencyEntry).key)
+	}
+	return entry, true
+}
+
+// complete records the outcome of a reserved key
+// only an applied write is final, a failed one releases the key so a retry runs again
+func (c *idempotencyCache) complete(entry *idempotencyEntry, ack *pb.IngestAck) {
+	if codes.Code(ack.GetCode()) != codes.OK {
+		c.mu.Lock()
+		if elem, ok := c.entries[entry.key]; ok && elem.Value == entry {
+			c.order.Remove(elem)
+			delete(c.entries, entry.key)
+		}
+		c.mu.Unlock()
+	}
+
+	entry.ack = ack
+	close(entry.done)
+}
+
+// wait returns a copy of the first attempt's ack marked as a duplicate
+// or nil when that attempt failed, the caller then tries the key itself
+func (e *idempotencyEntry) wait(ctx context.Context) (*pb.IngestAck, error) {
+	select {
+	case <-e.done:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	if codes.Code(e.ack.GetCode()) != codes.OK {
+		return nil, nil
+	}
+
+	ack := proto.Clone(e.ack).(*pb.IngestAck)
+	ack.Duplicate = true
+	return ack, nil
+}
@@ -0,0 +1,131 @@
+package gapi
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"time"
+
+	pb "github.com/Udehlee/tweet-stream/pb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// pressurePoll is how often a paused ingest stream looks at the pipeline again
+const pressurePoll = 20 * time.Millisecond
+
+// IngestTweets applies writes in the order they arrive and acks each one
+// the next request is only read once the pipeline has room, so a producer
+// outrunning the hub or the aggregator blocks on flow control
+// each write also waits for room to publish its event, a write acked OK was streamed
+func (s *StreamServer) IngestTweets(stream pb.TweetService_IngestTweetsServer) error {
+	ctx := stream.Context()
+
+	for {
+		if err := s.waitForCapacity(ctx); err != nil {
+			return status.FromContextError(err).Err()
+		}
+
+		req, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		ack, err := s.ingest(ctx, req)
+		if err != nil {
+			return status.FromContextError(err).Err()
+		}
+		if err := stream.Send(ack); err != nil {
+			return err
+		}
+	}
+}
+
+// ingest applies one request, or answers with the ack of the write its idempotency key already applied
+func (s *StreamServer) ingest(ctx context.Context, req *pb.IngestRequest) (*pb.IngestAck, error) {
+	key := req.GetIdempotencyKey()
+	if key == "" {
+		return s.applyIngest(ctx, req), nil
+	}
+
+	for {
+		entry, owner := s.idempotency.reserve(key)
+		if owner {
+			ack := s.applyIngest(ctx, req)
+			s.idempotency.complete(entry, ack)
+			return ack, nil
+		}
+
+		ack, err := entry.wait(ctx)
+		if ack != nil || err != nil {
+			return ack, err
+		}
+	}
+}
+
+// applyIngest runs the write through the same handler as its unary RPC
+func (s *StreamServer) applyIngest(ctx context.Context, req *pb.IngestRequest) *pb.IngestAck {
+	var tweet *pb.Tweet
+	var err error
+
+	switch op := req.GetOp().(type) {
+	case *pb.IngestRequest_Create:
+		tweet, err = s.CreateTweet(ctx, op.Create)
+	case *pb.IngestRequest_Update:
+		tweet, err = s.UpdateTweet(ctx, op.Update)
+	case *pb.IngestRequest_Delete:
+		tweet, err = s.DeleteTweet(ctx, op.Delete)
+	case *pb.IngestRequest_Reaction:
+		tweet, err = s.AddReaction(ctx, op.Reaction)
+	case *pb.IngestRequest_Comment:
+		tweet, err = s.AddComment(ctx, op.Comment)
+	default:
+		err = status.Error(codes.InvalidArgument, "op is required")
+	}
+
+	ack := &pb.IngestAck{
+		IdempotencyKey: req.GetIdempotencyKey(),
+		TweetId:        tweet.GetId(),
+	}
+	if err != nil {
+		st := status.Convert(err)
+		ack.Code = int32(st.Code())
+		ack.Error = st.Message()
+	}
+	return ack
+}
+
+// waitForCapacity blocks once the pipeline is filled past the high watermark
+// until it drains below half of it, so a paused stream does not flap around the mark
+// it keeps producers from reading ahead, each write still waits for room to publish
+func (s *StreamServer) waitForCapacity(ctx context.Context) error {
+	if s.pressure == nil {
+		return nil
+	}
+
+	level := s.pressure()
+	if level < s.highWatermark {
+		return nil
+	}
+
+	paused := time.Now()
+	ticker := time.NewTicker(pressurePoll)
+	defer ticker.Stop()
+
+	for s.pressure() >= s.highWatermark/2 {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+	// short pauses are routine under bulk load, only long ones are worth a line
+	if waited := time.Since(paused); waited >= time.Second {
+		log.Printf("gapi: ingest paused for %s, pipeline was %.0f%% full", waited.Round(time.Millisecond), level*100)
+	}
+	return nil
+}
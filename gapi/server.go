@@ -1,28 +1,152 @@
 package gapi
 
 import (
+	"errors"
+
+	"github.com/Udehlee/tweet-stream/internals/data/simulated"
+	"github.com/Udehlee/tweet-stream/internals/events"
+	"github.com/Udehlee/tweet-stream/internals/hub"
 	"github.com/Udehlee/tweet-stream/models"
 	pb "github.com/Udehlee/tweet-stream/pb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 type StreamServer struct {
 	pb.UnimplementedTweetServiceServer
-	TweetsChan <-chan *models.Tweet
+	Hub     *hub.Hub
+	Users   *simulated.UserRegistry
+	Tweets  *simulated.TweetService
+	Emitter *events.Emitter // shared with the generator so every change gets the next sequence
+
+	idempotency   *idempotencyCache
+	pressure      func() float64 // how full the pipeline is, from 0 to 1
+	highWatermark float64
+}
+
+type ServerOption func(*StreamServer)
+
+func NewStreamServer(h *hub.Hub, users *simulated.UserRegistry, tweets *simulated.TweetService, emitter *events.Emitter, opts ...ServerOption) *StreamServer {
+	s := &StreamServer{
+		Hub:           h,
+		Users:         users,
+		Tweets:        tweets,
+		Emitter:       emitter,
+		idempotency:   newIdempotencyCache(100000),
+		highWatermark: 0.8,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// WithIdempotencyKeys sets how many ingest keys are remembered
+func WithIdempotencyKeys(n int) ServerOption {
+	return func(s *StreamServer) {
+		if n > 0 {
+			s.idempotency = newIdempotencyCache(n)
+		}
+	}
 }
 
-func NewStreamServer(tweetCh <-chan *models.Tweet) *StreamServer {
-	return &StreamServer{
-		TweetsChan: tweetCh,
+// WithBackpressure pauses ingest streams while pressure reports the pipeline
+// filled to highWatermark or more
+func WithBackpressure(pressure func() float64, highWatermark float64) ServerOption {
+	return func(s *StreamServer) {
+		s.pressure = pressure
+		if highWatermark > 0 {
+			s.highWatermark = highWatermark
+		}
 	}
 }
 
-func (s *StreamServer) StreamTweets(req *pb.Empty, stream pb.TweetService_StreamTweetsServer) error {
+// StreamTweets registers a hub subscriber for the lifetime of the call
+// and sends every event that matches the request filters
+func (s *StreamServer) StreamTweets(req *pb.StreamRequest, stream pb.TweetService_StreamTweetsServer) error {
+	filter, err := NewFilter(req)
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	sub, backlog, err := s.subscribe(req)
+	if err != nil {
+		return err
+	}
+	defer s.Hub.Unsubscribe(sub)
+
 	c := NewConverter(WithProto())
-	for tweet := range s.TweetsChan {
-		protoTweet := c.Convert(tweet).(*pb.Tweet)
-		if err := stream.Send(protoTweet); err != nil {
+	for _, event := range backlog {
+		if !filter.Match(event) {
+			continue
+		}
+		if err := stream.Send(c.Convert(event).(*pb.TweetEvent)); err != nil {
 			return err
 		}
 	}
-	return nil
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+
+		case <-sub.Done():
+			if errors.Is(sub.Err(), hub.ErrSlowConsumer) {
+				return status.Errorf(codes.ResourceExhausted, "%v after %d dropped events", sub.Err(), sub.Dropped())
+			}
+			if errors.Is(sub.Err(), hub.ErrHubClosed) {
+				// the service is shutting down, hand over what was already queued
+				if err := s.sendQueued(sub, filter, c, stream); err != nil {
+					return err
+				}
+				return status.Error(codes.Unavailable, sub.Err().Error())
+			}
+			return nil
+
+		case event := <-sub.Events():
+			if !filter.Match(event) {
+				continue
+			}
+			protoEvent := c.Convert(event).(*pb.TweetEvent)
+			if err := stream.Send(protoEvent); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// sendQueued sends the events left in a removed subscriber's queue
+func (s *StreamServer) sendQueued(sub *hub.Subscriber, filter *Filter, c *Converter, stream pb.TweetService_StreamTweetsServer) error {
+	for {
+		select {
+		case event := <-sub.Events():
+			if !filter.Match(event) {
+				continue
+			}
+			if err := stream.Send(c.Convert(event).(*pb.TweetEvent)); err != nil {
+				return err
+			}
+		default:
+			return nil
+		}
+	}
+}
+
+// subscribe registers the subscriber and, when the request resumes
+// from an offset, returns the retained events the client missed
+func (s *StreamServer) subscribe(req *pb.StreamRequest) (*hub.Subscriber, []*models.TweetEvent, error) {
+	if req.ResumeFromOffset == nil {
+		return s.Hub.Subscribe(), nil, nil
+	}
+
+	sub, backlog, err := s.Hub.SubscribeFrom(req.GetResumeFromOffset())
+	if err != nil {
+		var expired *hub.OffsetExpiredError
+		var ahead *hub.OffsetAheadError
+		if errors.As(err, &expired) || errors.As(err, &ahead) {
+			return nil, nil, status.Error(codes.OutOfRange, err.Error())
+		}
+		return nil, nil, status.Error(codes.FailedPrecondition, err.Error())
+	}
+	return sub, backlog, nil
 }
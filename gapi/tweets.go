@@ -0,0 +1,96 @@
+package gapi
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/Udehlee/tweet-stream/internals/data/simulated"
+	"github.com/Udehlee/tweet-stream/models"
+	pb "github.com/Udehlee/tweet-stream/pb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// GetTweet looks a live tweet up by id
+func (s *StreamServer) GetTweet(ctx context.Context, req *pb.GetTweetRequest) (*pb.Tweet, error) {
+	if req.GetId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "id is required")
+	}
+
+	tweet, ok := s.Tweets.GetTweet(req.GetId())
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "tweet with the id %s is not found", req.GetId())
+	}
+
+	return NewConverter(WithProto()).Convert(tweet).(*pb.Tweet), nil
+}
+
+// ListTweets pages through live tweets matching the request filters
+// the page token is the position of the last tweet returned, so pages
+// neither repeat nor skip tweets when others are created or deleted
+func (s *StreamServer) ListTweets(ctx context.Context, req *pb.ListTweetsRequest) (*pb.ListTweetsResponse, error) {
+	after, limit, err := parsePage(req.GetPageToken(), req.GetPageSize())
+	if err != nil {
+		return nil, err
+	}
+
+	query, err := tweetQuery(req.GetUserId(), req.GetHashtag(), req.GetCreatedAfter(), req.GetCreatedBefore())
+	if err != nil {
+		return nil, err
+	}
+
+	tweets, next := s.Tweets.ListTweets(query, uint64(after), limit)
+	return tweetsResponse(tweets, next), nil
+}
+
+// CountTweets counts live tweets matching the request filters
+func (s *StreamServer) CountTweets(ctx context.Context, req *pb.CountTweetsRequest) (*pb.CountTweetsResponse, error) {
+	query, err := tweetQuery(req.GetUserId(), req.GetHashtag(), req.GetCreatedAfter(), req.GetCreatedBefore())
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.CountTweetsResponse{Count: int64(s.Tweets.CountTweets(query))}, nil
+}
+
+// tweetQuery validates the filters shared by ListTweets and CountTweets
+func tweetQuery(userID, hashtag string, after, before *timestamppb.Timestamp) (simulated.TweetQuery, error) {
+	query := simulated.TweetQuery{
+		UserID:  userID,
+		Hashtag: hashtag,
+	}
+
+	if after != nil {
+		if err := after.CheckValid(); err != nil {
+			return query, status.Errorf(codes.InvalidArgument, "invalid created_after: %v", err)
+		}
+		query.From = after.AsTime()
+	}
+	if before != nil {
+		if err := before.CheckValid(); err != nil {
+			return query, status.Errorf(codes.InvalidArgument, "invalid created_before: %v", err)
+		}
+		query.To = before.AsTime()
+	}
+
+	if !query.From.IsZero() && !query.To.IsZero() && !query.From.Before(query.To) {
+		return query, status.Error(codes.InvalidArgument, "created_after must be before created_before")
+	}
+	return query, nil
+}
+
+// tweetsResponse converts a page of tweets and its next cursor
+func tweetsResponse(tweets []*models.Tweet, next uint64) *pb.ListTweetsResponse {
+	c := NewConverter(WithProto())
+	resp := &pb.ListTweetsResponse{
+		Tweets: make([]*pb.Tweet, len(tweets)),
+	}
+	for i, t := range tweets {
+		resp.Tweets[i] = c.Convert(t).(*pb.Tweet)
+	}
+	if next > 0 {
+		resp.NextPageToken = strconv.FormatUint(next, 10)
+	}
+	return resp
+}
@@ -0,0 +1,105 @@
+package gapi
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/Udehlee/tweet-stream/models"
+	pb "github.com/Udehlee/tweet-stream/pb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	defaultPageSize = 50
+	maxPageSize     = 500
+)
+
+// GetUser looks a user up by id
+func (s *StreamServer) GetUser(ctx context.Context, req *pb.GetUserRequest) (*pb.User, error) {
+	if req.GetUserId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "user_id is required")
+	}
+
+	user, ok := s.Users.Get(req.GetUserId())
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "user with the id %s is not found", req.GetUserId())
+	}
+
+	return NewConverter(WithProto()).Convert(user).(*pb.User), nil
+}
+
+// ListUsers pages through the simulated user pool
+func (s *StreamServer) ListUsers(ctx context.Context, req *pb.ListUsersRequest) (*pb.ListUsersResponse, error) {
+	offset, limit, err := parsePage(req.GetPageToken(), req.GetPageSize())
+	if err != nil {
+		return nil, err
+	}
+
+	users, next := s.Users.List(offset, limit)
+	return usersResponse(users, next), nil
+}
+
+// ListFollowers pages through the followers of a user
+func (s *StreamServer) ListFollowers(ctx context.Context, req *pb.ListFollowersRequest) (*pb.ListUsersResponse, error) {
+	offset, limit, err := parsePage(req.GetPageToken(), req.GetPageSize())
+	if err != nil {
+		return nil, err
+	}
+
+	users, next, err := s.Users.Followers(req.GetUserId(), offset, limit)
+	if err != nil {
+		return nil, status.Error(codes.NotFound, err.Error())
+	}
+	return usersResponse(users, next), nil
+}
+
+// ListFollowing pages through the accounts a user follows
+func (s *StreamServer) ListFollowing(ctx context.Context, req *pb.ListFollowingRequest) (*pb.ListUsersResponse, error) {
+	offset, limit, err := parsePage(req.GetPageToken(), req.GetPageSize())
+	if err != nil {
+		return nil, err
+	}
+
+	users, next, err := s.Users.Following(req.GetUserId(), offset, limit)
+	if err != nil {
+		return nil, status.Error(codes.NotFound, err.Error())
+	}
+	return usersResponse(users, next), nil
+}
+
+// usersResponse converts a page of users and its next offset
+func usersResponse(users []*models.User, next int) *pb.ListUsersResponse {
+	c := NewConverter(WithProto())
+	resp := &pb.ListUsersResponse{
+		Users: make([]*pb.User, len(users)),
+	}
+	for i, u := range users {
+		resp.Users[i] = c.Convert(u).(*pb.User)
+	}
+	if next > 0 {
+		resp.NextPageToken = strconv.Itoa(next)
+	}
+	return resp
+}
+
+// parsePage turns a page token and size into an offset and a limit
+func parsePage(token string, size int32) (int, int, error) {
+	limit := int(size)
+	if limit <= 0 {
+		limit = defaultPageSize
+	}
+	if limit > maxPageSize {
+		limit = maxPageSize
+	}
+
+	if token == "" {
+		return 0, limit, nil
+	}
+
+	offset, err := strconv.Atoi(token)
+	if err != nil || offset < 0 {
+		return 0, 0, status.Errorf(codes.InvalidArgument, "invalid page_token %q", token)
+	}
+	return offset, limit, nil
+}
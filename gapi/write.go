@@ -0,0 +1,170 @@
+package gapi
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/Udehlee/tweet-stream/internals/data/simulated"
+	"github.com/Udehlee/tweet-stream/internals/events"
+	"github.com/Udehlee/tweet-stream/models"
+	pb "github.com/Udehlee/tweet-stream/pb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// maxMessageLength caps tweet and comment text in runes
+const maxMessageLength = 280
+
+// CreateTweet posts a tweet and publishes its CREATED event
+func (s *StreamServer) CreateTweet(ctx context.Context, req *pb.CreateTweetRequest) (*pb.Tweet, error) {
+	if err := validateText("message", req.GetMessage()); err != nil {
+		return nil, err
+	}
+
+	var author *models.User
+	if req.GetUserId() != "" {
+		user, err := s.user(req.GetUserId())
+		if err != nil {
+			return nil, err
+		}
+		author = user
+	}
+
+	var tweet *models.Tweet
+	return s.apply(ctx, &tweet, func() (models.EventType, *models.Tweet, *models.Tweet, error) {
+		created, err := s.Tweets.CreateTweetBy(author, req.GetMessage())
+		tweet = created
+		return models.EventCreated, created, nil, err
+	})
+}
+
+// UpdateTweet replaces the message of a tweet and publishes its UPDATED event
+func (s *StreamServer) UpdateTweet(ctx context.Context, req *pb.UpdateTweetRequest) (*pb.Tweet, error) {
+	if req.GetId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "id is required")
+	}
+	if err := validateText("message", req.GetMessage()); err != nil {
+		return nil, err
+	}
+
+	var tweet *models.Tweet
+	return s.apply(ctx, &tweet, func() (models.EventType, *models.Tweet, *models.Tweet, error) {
+		previous, updated, err := s.Tweets.UpdateTweet(req.GetId(), req.GetMessage())
+		tweet = updated
+		return models.EventUpdated, updated, previous, err
+	})
+}
+
+// DeleteTweet deletes a tweet, returns its last version and publishes its DELETED event
+func (s *StreamServer) DeleteTweet(ctx context.Context, req *pb.DeleteTweetRequest) (*pb.Tweet, error) {
+	if req.GetId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "id is required")
+	}
+
+	var tweet *models.Tweet
+	return s.apply(ctx, &tweet, func() (models.EventType, *models.Tweet, *models.Tweet, error) {
+		deleted, err := s.Tweets.DeleteTweet(req.GetId())
+		tweet = deleted
+		return models.EventDeleted, deleted, nil, err
+	})
+}
+
+// AddReaction likes or retweets a tweet and publishes its UPDATED event
+func (s *StreamServer) AddReaction(ctx context.Context, req *pb.AddReactionRequest) (*pb.Tweet, error) {
+	if req.GetTweetId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "tweet_id is required")
+	}
+
+	reactionType := strings.ToLower(strings.TrimSpace(req.GetReactionType()))
+	if reactionType != models.ReactionLike && reactionType != models.ReactionRetweet {
+		return nil, status.Errorf(codes.InvalidArgument, "reaction_type must be %q or %q", models.ReactionLike, models.ReactionRetweet)
+	}
+
+	user, err := s.user(req.GetUserId())
+	if err != nil {
+		return nil, err
+	}
+
+	var tweet *models.Tweet
+	return s.apply(ctx, &tweet, func() (models.EventType, *models.Tweet, *models.Tweet, error) {
+		previous, updated, err := s.Tweets.AddReaction(req.GetTweetId(), user, reactionType)
+		tweet = updated
+		return models.EventUpdated, updated, previous, err
+	})
+}
+
+// AddComment comments on a tweet and publishes its UPDATED event
+func (s *StreamServer) AddComment(ctx context.Context, req *pb.AddCommentRequest) (*pb.Tweet, error) {
+	if req.GetTweetId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "tweet_id is required")
+	}
+	if err := validateText("content", req.GetContent()); err != nil {
+		return nil, err
+	}
+
+	user, err := s.user(req.GetUserId())
+	if err != nil {
+		return nil, err
+	}
+
+	var tweet *models.Tweet
+	return s.apply(ctx, &tweet, func() (models.EventType, *models.Tweet, *models.Tweet, error) {
+		previous, updated, err := s.Tweets.AddComment(req.GetTweetId(), user, req.GetContent())
+		tweet = updated
+		return models.EventUpdated, updated, previous, err
+	})
+}
+
+// user resolves a required user id from the simulated pool
+func (s *StreamServer) user(userID string) (*models.User, error) {
+	if userID == "" {
+		return nil, status.Error(codes.InvalidArgument, "user_id is required")
+	}
+
+	user, ok := s.Users.Get(userID)
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "user with the id %s is not found", userID)
+	}
+	return user, nil
+}
+
+// apply runs a store change through the emitter shared with the generator
+// and returns the tweet the change stored in *tweet
+// unlike generated changes a write is only made once its event can be published,
+// a write that could not wait for room is not applied and fails as RESOURCE_EXHAUSTED
+func (s *StreamServer) apply(ctx context.Context, tweet **models.Tweet, change events.Change) (*pb.Tweet, error) {
+	if _, err := s.Emitter.ApplyWait(ctx, change); err != nil {
+		return nil, storeError(err)
+	}
+	return NewConverter(WithProto()).Convert(*tweet).(*pb.Tweet), nil
+}
+
+// validateText checks that a message is set and fits in a tweet
+func validateText(field, text string) error {
+	if strings.TrimSpace(text) == "" {
+		return status.Errorf(codes.InvalidArgument, "%s is required", field)
+	}
+	if n := utf8.RuneCountInString(text); n > maxMessageLength {
+		return status.Errorf(codes.InvalidArgument, "%s is %d characters, the limit is %d", field, n, maxMessageLength)
+	}
+	return nil
+}
+
+// storeError maps tweet store and emitter errors to gRPC status codes
+func storeError(err error) error {
+	var notFound *simulated.TweetNotFoundError
+	var duplicate *simulated.DuplicateReactionError
+	switch {
+	case errors.As(err, &notFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.As(err, &duplicate):
+		return status.Error(codes.AlreadyExists, err.Error())
+	case errors.Is(err, events.ErrPublishFull):
+		return status.Error(codes.ResourceExhausted, "the event stream is full, the write was not applied")
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return status.Error(codes.ResourceExhausted, "the event stream stayed full until the request ended, the write was not applied")
+	}
+	return status.Error(codes.Internal, err.Error())
+}
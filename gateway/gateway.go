@@ -0,0 +1,278 @@
+// Package gateway serves the gRPC TweetService as JSON over HTTP
+// and the live stream as Server-Sent Events and over WebSocket
+package gateway
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Udehlee/tweet-stream/gapi"
+	"github.com/Udehlee/tweet-stream/internals/storage"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// maxBodySize caps request bodies, a tweet is far smaller
+const maxBodySize = 1 << 20
+
+var marshalOptions = protojson.MarshalOptions{UseProtoNames: true}
+
+// Gateway calls the gRPC handlers in process, so both transports share
+// validation, error codes and the published events
+type Gateway struct {
+	api       *gapi.StreamServer
+	mux       *http.ServeMux
+	metrics   *storage.BroadcastSink // windows pushed to WebSocket clients, nil pushes none
+	keepAlive time.Duration          // comment sent on idle event streams so proxies keep them open
+	heartbeat time.Duration          // WebSocket ping interval
+}
+
+type Option func(*Gateway)
+
+func NewGateway(api *gapi.StreamServer, opts ...Option) *Gateway {
+	g := &Gateway{
+		api:       api,
+		mux:       http.NewServeMux(),
+		keepAlive: 15 * time.Second,
+		heartbeat: 30 * time.Second,
+	}
+	for _, opt := range opts {
+		opt(g)
+	}
+	g.routes()
+	return g
+}
+
+// WithKeepAlive sets how often idle event streams get a keep alive comment
+func WithKeepAlive(interval time.Duration) Option {
+	return func(g *Gateway) {
+		if interval > 0 {
+			g.keepAlive = interval
+		}
+	}
+}
+
+// WithMetrics pushes the windows inserted into sink to WebSocket clients
+func WithMetrics(sink *storage.BroadcastSink) Option {
+	return func(g *Gateway) {
+		g.metrics = sink
+	}
+}
+
+// WithHeartbeat sets how often WebSocket clients are pinged
+// a client that misses two pings in a row is disconnected
+func WithHeartbeat(interval time.Duration) Option {
+	return func(g *Gateway) {
+		if interval > 0 {
+			g.heartbeat = interval
+		}
+	}
+}
+
+func (g *Gateway) routes() {
+	g.mux.HandleFunc("GET /v1/tweets", g.listTweets)
+	g.mux.HandleFunc("GET /v1/tweets/count", g.countTweets)
+	g.mux.HandleFunc("GET /v1/tweets/{id}", g.getTweet)
+	g.mux.HandleFunc("POST /v1/tweets", g.createTweet)
+	g.mux.HandleFunc("PATCH /v1/tweets/{id}", g.updateTweet)
+	g.mux.HandleFunc("DELETE /v1/tweets/{id}", g.deleteTweet)
+	g.mux.HandleFunc("POST /v1/tweets/{id}/reactions", g.addReaction)
+	g.mux.HandleFunc("POST /v1/tweets/{id}/comments", g.addComment)
+
+	g.mux.HandleFunc("GET /v1/users", g.listUsers)
+	g.mux.HandleFunc("GET /v1/users/{id}", g.getUser)
+	g.mux.HandleFunc("GET /v1/users/{id}/followers", g.listFollowers)
+	g.mux.HandleFunc("GET /v1/users/{id}/following", g.listFollowing)
+
+	g.mux.HandleFunc("GET /v1/stream", g.stream)
+	g.mux.HandleFunc("GET /v1/ws", g.wsStream)
+}
+
+func (g *Gateway) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	g.mux.ServeHTTP(w, r)
+}
+
+// respond writes the result of a gRPC handler
+func respond(w http.ResponseWriter, msg proto.Message, err error) {
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeMessage(w, http.StatusOK, msg)
+}
+
+func writeMessage(w http.ResponseWriter, code int, msg proto.Message) {
+	data, err := marshalOptions.Marshal(msg)
+	if err != nil {
+		writeError(w, status.Errorf(codes.Internal, "failed to encode response: %v", err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	w.Write(data)
+}
+
+// errorBody is the JSON shape of every error response
+type errorBody struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// writeError answers with the HTTP status matching the gRPC status of err
+func writeError(w http.ResponseWriter, err error) {
+	st := status.Convert(err)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(httpStatus(st.Code()))
+	json.NewEncoder(w).Encode(errorBody{
+		Code:    codeName(st.Code()),
+		Message: st.Message(),
+	})
+}
+
+// codeName spells a code the way the gRPC docs do, e.g. NOT_FOUND
+func codeName(code codes.Code) string {
+	if code == codes.OK {
+		return "OK"
+	}
+
+	var b strings.Builder
+	for i, r := range code.String() {
+		if i > 0 && r >= 'A' && r <= 'Z' {
+			b.WriteByte('_')
+		}
+		b.WriteRune(r)
+	}
+	return strings.ToUpper(b.String())
+}
+
+// httpStatus maps gRPC codes the way grpc-gateway does
+func httpStatus(code codes.Code) int {
+	switch code {
+	case codes.OK:
+		return http.StatusOK
+	case codes.InvalidArgument, codes.OutOfRange, codes.FailedPrecondition:
+		return http.StatusBadRequest
+	case codes.Unauthenticated:
+		return http.StatusUnauthorized
+	case codes.PermissionDenied:
+		return http.StatusForbidden
+	case codes.NotFound:
+		return http.StatusNotFound
+	case codes.AlreadyExists, codes.Aborted:
+		return http.StatusConflict
+	case codes.ResourceExhausted:
+		return http.StatusTooManyRequests
+	case codes.Canceled:
+		return 499 // client closed request
+	case codes.Unimplemented:
+		return http.StatusNotImplemented
+	case codes.Unavailable:
+		return http.StatusServiceUnavailable
+	case codes.DeadlineExceeded:
+		return http.StatusGatewayTimeout
+	}
+	return http.StatusInternalServerError
+}
+
+// decodeBody reads a JSON request body into msg, unknown fields are errors
+func decodeBody(w http.ResponseWriter, r *http.Request, msg proto.Message) error {
+	data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBodySize))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			return status.Errorf(codes.InvalidArgument, "request body is larger than %d bytes", maxBodySize)
+		}
+		return status.Errorf(codes.InvalidArgument, "failed to read request body: %v", err)
+	}
+	if len(data) == 0 {
+		return nil
+	}
+	if err := protojson.Unmarshal(data, msg); err != nil {
+		return status.Errorf(codes.InvalidArgument, "invalid request body: %v", err)
+	}
+	return nil
+}
+
+// query reads the typed query parameters of a request and remembers the first bad one
+type query struct {
+	values map[string][]string
+	err    error
+}
+
+func newQuery(r *http.Request) *query {
+	return &query{values: r.URL.Query()}
+}
+
+func (q *query) fail(name, format string, args ...any) {
+	if q.err == nil {
+		q.err = status.Errorf(codes.InvalidArgument, "query parameter %s: %s", name, fmt.Sprintf(format, args...))
+	}
+}
+
+func (q *query) string(name string) string {
+	if v := q.values[name]; len(v) > 0 {
+		return strings.TrimSpace(v[0])
+	}
+	return ""
+}
+
+// list accepts both repeated parameters and comma separated values
+func (q *query) list(name string) []string {
+	var items []string
+	for _, v := range q.values[name] {
+		for _, item := range strings.Split(v, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+	}
+	return items
+}
+
+func (q *query) int32(name string) int32 {
+	v := q.string(name)
+	if v == "" {
+		return 0
+	}
+	n, err := strconv.ParseInt(v, 10, 32)
+	if err != nil {
+		q.fail(name, "%q is not an integer", v)
+	}
+	return int32(n)
+}
+
+func (q *query) bool(name string) bool {
+	v := q.string(name)
+	if v == "" {
+		return false
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		q.fail(name, "%q is not true or false", v)
+	}
+	return b
+}
+
+// timestamp parses an RFC 3339 time, nil when the parameter is not set
+func (q *query) timestamp(name string) *timestamppb.Timestamp {
+	v := q.string(name)
+	if v == "" {
+		return nil
+	}
+	t, err := time.Parse(time.RFC3339Nano, v)
+	if err != nil {
+		q.fail(name, "%q is not an RFC 3339 time", v)
+		return nil
+	}
+	return timestamppb.New(t)
+}
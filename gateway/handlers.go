@@ -0,0 +1,165 @@
+package gateway
+
+import (
+	"net/http"
+
+	pb "github.com/Udehlee/tweet-stream/pb"
+)
+
+// GET /v1/tweets?user_id=&hashtag=&created_after=&created_before=&page_size=&page_token=
+func (g *Gateway) listTweets(w http.ResponseWriter, r *http.Request) {
+	q := newQuery(r)
+	req := &pb.ListTweetsRequest{
+		UserId:        q.string("user_id"),
+		Hashtag:       q.string("hashtag"),
+		CreatedAfter:  q.timestamp("created_after"),
+		CreatedBefore: q.timestamp("created_before"),
+		PageSize:      q.int32("page_size"),
+		PageToken:     q.string("page_token"),
+	}
+	if q.err != nil {
+		writeError(w, q.err)
+		return
+	}
+
+	resp, err := g.api.ListTweets(r.Context(), req)
+	respond(w, resp, err)
+}
+
+// GET /v1/tweets/count takes the filters of GET /v1/tweets
+func (g *Gateway) countTweets(w http.ResponseWriter, r *http.Request) {
+	q := newQuery(r)
+	req := &pb.CountTweetsRequest{
+		UserId:        q.string("user_id"),
+		Hashtag:       q.string("hashtag"),
+		CreatedAfter:  q.timestamp("created_after"),
+		CreatedBefore: q.timestamp("created_before"),
+	}
+	if q.err != nil {
+		writeError(w, q.err)
+		return
+	}
+
+	resp, err := g.api.CountTweets(r.Context(), req)
+	respond(w, resp, err)
+}
+
+func (g *Gateway) getTweet(w http.ResponseWriter, r *http.Request) {
+	resp, err := g.api.GetTweet(r.Context(), &pb.GetTweetRequest{Id: r.PathValue("id")})
+	respond(w, resp, err)
+}
+
+// POST /v1/tweets with a CreateTweetRequest body
+func (g *Gateway) createTweet(w http.ResponseWriter, r *http.Request) {
+	req := &pb.CreateTweetRequest{}
+	if err := decodeBody(w, r, req); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	resp, err := g.api.CreateTweet(r.Context(), req)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	w.Header().Set("Location", "/v1/tweets/"+resp.GetId())
+	writeMessage(w, http.StatusCreated, resp)
+}
+
+// PATCH /v1/tweets/{id} with an UpdateTweetRequest body, the path sets the id
+func (g *Gateway) updateTweet(w http.ResponseWriter, r *http.Request) {
+	req := &pb.UpdateTweetRequest{}
+	if err := decodeBody(w, r, req); err != nil {
+		writeError(w, err)
+		return
+	}
+	req.Id = r.PathValue("id")
+
+	resp, err := g.api.UpdateTweet(r.Context(), req)
+	respond(w, resp, err)
+}
+
+// DELETE /v1/tweets/{id} answers with the last version of the tweet
+func (g *Gateway) deleteTweet(w http.ResponseWriter, r *http.Request) {
+	resp, err := g.api.DeleteTweet(r.Context(), &pb.DeleteTweetRequest{Id: r.PathValue("id")})
+	respond(w, resp, err)
+}
+
+// POST /v1/tweets/{id}/reactions with an AddReactionRequest body
+func (g *Gateway) addReaction(w http.ResponseWriter, r *http.Request) {
+	req := &pb.AddReactionRequest{}
+	if err := decodeBody(w, r, req); err != nil {
+		writeError(w, err)
+		return
+	}
+	req.TweetId = r.PathValue("id")
+
+	resp, err := g.api.AddReaction(r.Context(), req)
+	respond(w, resp, err)
+}
+
+// POST /v1/tweets/{id}/comments with an AddCommentRequest body
+func (g *Gateway) addComment(w http.ResponseWriter, r *http.Request) {
+	req := &pb.AddCommentRequest{}
+	if err := decodeBody(w, r, req); err != nil {
+		writeError(w, err)
+		return
+	}
+	req.TweetId = r.PathValue("id")
+
+	resp, err := g.api.AddComment(r.Context(), req)
+	respond(w, resp, err)
+}
+
+// GET /v1/users?page_size=&page_token=
+func (g *Gateway) listUsers(w http.ResponseWriter, r *http.Request) {
+	q := newQuery(r)
+	req := &pb.ListUsersRequest{
+		PageSize:  q.int32("page_size"),
+		PageToken: q.string("page_token"),
+	}
+	if q.err != nil {
+		writeError(w, q.err)
+		return
+	}
+
+	resp, err := g.api.ListUsers(r.Context(), req)
+	respond(w, resp, err)
+}
+
+func (g *Gateway) getUser(w http.ResponseWriter, r *http.Request) {
+	resp, err := g.api.GetUser(r.Context(), &pb.GetUserRequest{UserId: r.PathValue("id")})
+	respond(w, resp, err)
+}
+
+func (g *Gateway) listFollowers(w http.ResponseWriter, r *http.Request) {
+	q := newQuery(r)
+	req := &pb.ListFollowersRequest{
+		UserId:    r.PathValue("id"),
+		PageSize:  q.int32("page_size"),
+		PageToken: q.string("page_token"),
+	}
+	if q.err != nil {
+		writeError(w, q.err)
+		return
+	}
+
+	resp, err := g.api.ListFollowers(r.Context(), req)
+	respond(w, resp, err)
+}
+
+func (g *Gateway) listFollowing(w http.ResponseWriter, r *http.Request) {
+	q := newQuery(r)
+	req := &pb.ListFollowingRequest{
+		UserId:    r.PathValue("id"),
+		PageSize:  q.int32("page_size"),
+		PageToken: q.string("page_token"),
+	}
+	if q.err != nil {
+		writeError(w, q.err)
+		return
+	}
+
+	resp, err := g.api.ListFollowing(r.Context(), req)
+	respond(w, resp, err)
+}
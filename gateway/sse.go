@@ -0,0 +1,186 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Udehlee/tweet-stream/gapi"
+	pb "github.com/Udehlee/tweet-stream/pb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// GET /v1/stream takes the StreamTweets filters as query parameters
+// hashtags, user_ids, keywords and event_types are lists, an EventSource
+// reconnecting with Last-Event-ID resumes right after the last event it saw
+func (g *Gateway) stream(w http.ResponseWriter, r *http.Request) {
+	req, err := streamRequest(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	// the filter is built again by StreamTweets, checking it here keeps
+	// a bad filter a plain 400 instead of an error event
+	if _, err := gapi.NewFilter(req); err != nil {
+		writeError(w, status.Error(codes.InvalidArgument, err.Error()))
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, status.Error(codes.Unimplemented, "streaming is not supported by this connection"))
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	sse := &sseStream{ctx: r.Context(), w: w, flusher: flusher}
+	stop := sse.keepAlive(g.keepAlive)
+	defer stop()
+
+	if err := g.api.StreamTweets(req, sse); err != nil {
+		sse.sendError(err)
+	}
+}
+
+// streamRequest builds a StreamRequest from the query and the Last-Event-ID header
+func streamRequest(r *http.Request) (*pb.StreamRequest, error) {
+	q := newQuery(r)
+	req := &pb.StreamRequest{
+		Hashtags:     q.list("hashtags"),
+		UserIds:      q.list("user_ids"),
+		VerifiedOnly: q.bool("verified_only"),
+		Keywords:     q.list("keywords"),
+		MessageRegex: q.string("message_regex"),
+	}
+
+	for _, name := range q.list("event_types") {
+		eventType, ok := parseEventType(name)
+		if !ok {
+			q.fail("event_types", "%q is not created, updated or deleted", name)
+			continue
+		}
+		req.EventTypes = append(req.EventTypes, eventType)
+	}
+
+	if v := q.string("resume_from_offset"); v != "" {
+		offset, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			q.fail("resume_from_offset", "%q is not an offset", v)
+		}
+		req.ResumeFromOffset = &offset
+	}
+	if q.err != nil {
+		return nil, q.err
+	}
+
+	// an explicit resume_from_offset wins over the browser's reconnect header
+	if id := strings.TrimSpace(r.Header.Get("Last-Event-ID")); id != "" && req.ResumeFromOffset == nil {
+		last, err := strconv.ParseUint(id, 10, 64)
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "Last-Event-ID %q is not an offset", id)
+		}
+		next := last + 1
+		req.ResumeFromOffset = &next
+	}
+	return req, nil
+}
+
+// parseEventType accepts created as well as the enum name EVENT_TYPE_CREATED
+func parseEventType(name string) (pb.EventType, bool) {
+	name = strings.ToUpper(name)
+	if !strings.HasPrefix(name, "EVENT_TYPE_") {
+		name = "EVENT_TYPE_" + name
+	}
+	value, ok := pb.EventType_value[name]
+	if !ok || value == int32(pb.EventType_EVENT_TYPE_UNSPECIFIED) {
+		return 0, false
+	}
+	return pb.EventType(value), true
+}
+
+// sseStream lets StreamTweets write to an event stream as if it were a gRPC stream
+// only Send and Context are called by the handler
+type sseStream struct {
+	grpc.ServerStream
+
+	ctx     context.Context
+	mu      sync.Mutex // Send and the keep alive write from different goroutines
+	w       http.ResponseWriter
+	flusher http.Flusher
+}
+
+var _ grpc.ServerStreamingServer[pb.TweetEvent] = (*sseStream)(nil)
+
+func (s *sseStream) Context() context.Context {
+	return s.ctx
+}
+
+// Send writes one event, its id is the hub offset so a reconnect can resume
+func (s *sseStream) Send(event *pb.TweetEvent) error {
+	data, err := marshalOptions.Marshal(event)
+	if err != nil {
+		return status.Errorf(codes.Internal, "failed to encode event: %v", err)
+	}
+
+	eventType := strings.ToLower(strings.TrimPrefix(event.GetType().String(), "EVENT_TYPE_"))
+	return s.write(fmt.Sprintf("id: %d\nevent: %s\ndata: %s\n\n", event.GetOffset(), eventType, data))
+}
+
+// sendError ends the stream with an error event, the body matches the JSON error responses
+func (s *sseStream) sendError(err error) {
+	st := status.Convert(err)
+	if st.Code() == codes.Canceled {
+		return
+	}
+	data, _ := json.Marshal(errorBody{Code: codeName(st.Code()), Message: st.Message()})
+	s.write(fmt.Sprintf("event: error\ndata: %s\n\n", data))
+}
+
+// keepAlive writes a comment every interval until the returned stop is called
+// stop waits for the writer, the response must not be touched once the handler returns
+func (s *sseStream) keepAlive(interval time.Duration) (stop func()) {
+	done := make(chan struct{})
+	exited := make(chan struct{})
+	go func() {
+		defer close(exited)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-s.ctx.Done():
+				return
+			case <-ticker.C:
+				s.write(": keep-alive\n\n")
+			}
+		}
+	}()
+	return func() {
+		close(done)
+		<-exited
+	}
+}
+
+func (s *sseStream) write(frame string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := fmt.Fprint(s.w, frame); err != nil {
+		return err
+	}
+	s.flusher.Flush()
+	return nil
+}
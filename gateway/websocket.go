@@ -0,0 +1,315 @@
+package gateway
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Udehlee/tweet-stream/gapi"
+	"github.com/Udehlee/tweet-stream/internals/entities"
+	"github.com/Udehlee/tweet-stream/internals/hub"
+	"github.com/Udehlee/tweet-stream/models"
+	pb "github.com/Udehlee/tweet-stream/pb"
+	"github.com/gorilla/websocket"
+	"google.golang.org/grpc/codes"
+)
+
+const (
+	wsWriteWait      = 10 * time.Second // longest a single write may block
+	wsMaxMessageSize = 4096             // commands are small, anything larger closes the connection
+	wsMetricsBuffer  = 4                // windows queued for a connection before it misses some
+)
+
+var upgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 4096,
+}
+
+// wsCommand is a client message, type is subscribe or unsubscribe
+// {"type":"subscribe","hashtags":["golang"],"user_ids":["5AD8FA"],"keywords":["go"],"metrics":true}
+type wsCommand struct {
+	Type string `json:"type"`
+	wsTopics
+}
+
+// wsTopics is what a connection wants pushed
+// a tweet event is sent when it matches any hashtag, user or keyword
+type wsTopics struct {
+	Hashtags []string `json:"hashtags,omitempty"`
+	UserIDs  []string `json:"user_ids,omitempty"`
+	Keywords []string `json:"keywords,omitempty"`
+	Metrics  bool     `json:"metrics,omitempty"` // every closed aggregation window
+}
+
+// wsMessage is a server message, only the field named by type is set
+type wsMessage struct {
+	Type         string                `json:"type"` // subscribed, event, metrics or error
+	Subscription *wsTopics             `json:"subscription,omitempty"`
+	Event        json.RawMessage       `json:"event,omitempty"` // TweetEvent in the protobuf JSON mapping
+	Metrics      *models.WindowMetrics `json:"metrics,omitempty"`
+	Error        *errorBody            `json:"error,omitempty"`
+}
+
+// GET /v1/ws upgrades to a WebSocket fed by the hub that feeds StreamTweets
+// the connection joins the hub on its first subscribe and leaves it again
+// when no topics are left, the server pings every
+// heartbeat and drops a connection that misses two pongs in a row
+func (g *Gateway) wsStream(w http.ResponseWriter, r *http.Request) {
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return // Upgrade has answered with an HTTP error
+	}
+	defer conn.Close()
+
+	// the hub and the metrics sink are only subscribed to while the client
+	// wants something from them, nil channels keep their cases quiet until then
+	var sub *hub.Subscriber
+	var events <-chan *models.TweetEvent
+	var subDone <-chan struct{}
+	var metrics <-chan models.WindowMetrics
+	cancelMetrics := func() {}
+	defer func() {
+		if sub != nil {
+			g.api.Hub.Unsubscribe(sub)
+		}
+		cancelMetrics()
+	}()
+
+	pongWait := 2 * g.heartbeat
+	conn.SetReadLimit(wsMaxMessageSize)
+	conn.SetReadDeadline(time.Now().Add(pongWait))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(pongWait))
+	})
+
+	done := make(chan struct{})
+	defer close(done)
+	commands := make(chan []byte)
+	readErr := make(chan error, 1)
+	go readCommands(conn, pongWait, commands, readErr, done)
+
+	heartbeat := time.NewTicker(g.heartbeat)
+	defer heartbeat.Stop()
+
+	topics := newWSSubscription()
+	c := gapi.NewConverter(gapi.WithProto())
+
+	for {
+		var msg *wsMessage
+		select {
+		case err := <-readErr:
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) {
+				log.Printf("gateway: websocket from %s closed: %v", r.RemoteAddr, err)
+			}
+			return
+
+		case data := <-commands:
+			msg = topics.handle(data)
+
+			switch {
+			case len(topics.filters) > 0 && sub == nil:
+				sub = g.api.Hub.Subscribe()
+				events, subDone = sub.Events(), sub.Done()
+			case len(topics.filters) == 0 && sub != nil:
+				g.api.Hub.Unsubscribe(sub)
+				sub, events, subDone = nil, nil, nil
+			}
+
+			switch {
+			case topics.metrics && metrics == nil && g.metrics != nil:
+				metrics, cancelMetrics = g.metrics.Subscribe(wsMetricsBuffer)
+			case !topics.metrics && metrics != nil:
+				cancelMetrics()
+				metrics, cancelMetrics = nil, func() {}
+			}
+
+		case event := <-events:
+			if !topics.match(event) {
+				continue
+			}
+			data, err := marshalOptions.Marshal(c.Convert(event).(*pb.TweetEvent))
+			if err != nil {
+				log.Printf("gateway: failed to encode event %s: %v", event.EventID, err)
+				continue
+			}
+			msg = &wsMessage{Type: "event", Event: data}
+
+		case <-subDone:
+			closeWS(conn, sub.Err())
+			return
+
+		case window, ok := <-metrics:
+			if !ok {
+				metrics = nil
+				continue
+			}
+			msg = &wsMessage{Type: "metrics", Metrics: &window}
+
+		case <-heartbeat.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(wsWriteWait)); err != nil {
+				return
+			}
+			continue
+		}
+
+		conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+		if err := conn.WriteJSON(msg); err != nil {
+			return
+		}
+	}
+}
+
+// readCommands hands text messages to the writer until the connection fails
+// gorilla allows one reader and one writer, so only the handler loop writes
+func readCommands(conn *websocket.Conn, pongWait time.Duration, commands chan<- []byte, readErr chan<- error, done <-chan struct{}) {
+	for {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			readErr <- err
+			return
+		}
+		// any message shows the client is alive
+		conn.SetReadDeadline(time.Now().Add(pongWait))
+
+		select {
+		case commands <- data:
+		case <-done:
+			return
+		}
+	}
+}
+
+// closeWS tells the client why the hub removed its subscriber
+func closeWS(conn *websocket.Conn, reason error) {
+	code, text := websocket.CloseInternalServerErr, "stream ended"
+	switch {
+	case errors.Is(reason, hub.ErrHubClosed):
+		code, text = websocket.CloseGoingAway, "service is shutting down"
+	case errors.Is(reason, hub.ErrSlowConsumer):
+		code, text = websocket.CloseTryAgainLater, reason.Error()
+	}
+	conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(code, text), time.Now().Add(wsWriteWait))
+}
+
+// wsSubscription holds the normalized topics of one connection
+// and one filter per kind of topic, built by the same code as StreamTweets filters
+type wsSubscription struct {
+	hashtags map[string]struct{}
+	userIDs  map[string]struct{}
+	keywords map[string]struct{}
+	metrics  bool
+	filters  []*gapi.Filter
+}
+
+func newWSSubscription() *wsSubscription {
+	return &wsSubscription{
+		hashtags: make(map[string]struct{}),
+		userIDs:  make(map[string]struct{}),
+		keywords: make(map[string]struct{}),
+	}
+}
+
+// handle applies one command and answers with the whole subscription or an error
+func (s *wsSubscription) handle(data []byte) *wsMessage {
+	var cmd wsCommand
+	if err := json.Unmarshal(data, &cmd); err != nil {
+		return wsError(codes.InvalidArgument, "invalid message: "+err.Error())
+	}
+
+	switch cmd.Type {
+	case "subscribe":
+		s.update(cmd.wsTopics, true)
+	case "unsubscribe":
+		s.update(cmd.wsTopics, false)
+	default:
+		return wsError(codes.InvalidArgument, `type must be "subscribe" or "unsubscribe"`)
+	}
+
+	if err := s.build(); err != nil {
+		return wsError(codes.InvalidArgument, err.Error())
+	}
+	return &wsMessage{Type: "subscribed", Subscription: s.topics()}
+}
+
+func (s *wsSubscription) update(topics wsTopics, add bool) {
+	set := func(m map[string]struct{}, items []string, normalize func(string) string) {
+		for _, item := range items {
+			if item = normalize(item); item == "" {
+				continue
+			}
+			if add {
+				m[item] = struct{}{}
+			} else {
+				delete(m, item)
+			}
+		}
+	}
+	set(s.hashtags, topics.Hashtags, entities.NormalizeHashtag)
+	set(s.userIDs, topics.UserIDs, strings.TrimSpace)
+	set(s.keywords, topics.Keywords, func(kw string) string { return strings.ToLower(strings.TrimSpace(kw)) })
+
+	// metrics only changes when the command names it
+	if topics.Metrics {
+		s.metrics = add
+	}
+}
+
+// build replaces the filters, each kind of topic gets its own so they are ORed
+func (s *wsSubscription) build() error {
+	var requests []*pb.StreamRequest
+	if len(s.hashtags) > 0 {
+		requests = append(requests, &pb.StreamRequest{Hashtags: sortedKeys(s.hashtags)})
+	}
+	if len(s.userIDs) > 0 {
+		requests = append(requests, &pb.StreamRequest{UserIds: sortedKeys(s.userIDs)})
+	}
+	if len(s.keywords) > 0 {
+		requests = append(requests, &pb.StreamRequest{Keywords: sortedKeys(s.keywords)})
+	}
+
+	filters := make([]*gapi.Filter, 0, len(requests))
+	for _, req := range requests {
+		filter, err := gapi.NewFilter(req)
+		if err != nil {
+			return err
+		}
+		filters = append(filters, filter)
+	}
+	s.filters = filters
+	return nil
+}
+
+func (s *wsSubscription) match(event *models.TweetEvent) bool {
+	for _, filter := range s.filters {
+		if filter.Match(event) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *wsSubscription) topics() *wsTopics {
+	return &wsTopics{
+		Hashtags: sortedKeys(s.hashtags),
+		UserIDs:  sortedKeys(s.userIDs),
+		Keywords: sortedKeys(s.keywords),
+		Metrics:  s.metrics,
+	}
+}
+
+func wsError(code codes.Code, message string) *wsMessage {
+	return &wsMessage{Type: "error", Error: &errorBody{Code: codeName(code), Message: message}}
+}
+
+func sortedKeys(m map[string]struct{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
@@ -6,122 +6,356 @@ import (
 	"sort"
 	"time"
 
+	"github.com/Udehlee/tweet-stream/internals/anomaly"
+	"github.com/Udehlee/tweet-stream/internals/entities"
+	"github.com/Udehlee/tweet-stream/internals/sketch"
 	"github.com/Udehlee/tweet-stream/internals/storage"
+	"github.com/Udehlee/tweet-stream/internals/trending"
 	"github.com/Udehlee/tweet-stream/models"
 )
 
+// TweetAggregator runs every window spec over the same event stream
 type TweetAggregator struct {
-	Batch          []*models.Tweet
-	WindowDuration time.Duration
-	InChan         <-chan *models.Tweet
-	InfluxWriter   *storage.InfluxWriter
+	Windows         []WindowSpec
+	WatermarkDelay  time.Duration // how far event time may run behind the newest event before a window closes
+	AllowedLateness time.Duration // how long a closed window still accepts late events
+	IdleTimeout     time.Duration // quiet time after which event time follows the wall clock, 0 never advances it
+	Anomaly         anomaly.Config
+	Trending        *trending.Tracker // hashtag trends over horizons longer than any window
+	TopHashtags     int               // hashtags in TrendingHashtags
+	TopTrending     int               // hashtags in TrendingNow
+	EmitTime        bool              // window events by emit time instead of when the tweet changed
+	InChan          <-chan *models.TweetEvent
+	Sink            storage.MetricsSink
+
+	sets      []*windowSet
+	watermark time.Time
+	newest    time.Time // latest event time seen
+	arrived   time.Time // wall time the last event arrived
+	settings  chan Settings
+	done      chan struct{}
+}
+
+// Settings are the aggregator settings that can change while it runs
+type Settings struct {
+	AnomalyThreshold float64
+	TopHashtags      int
+	TopTrending      int
+}
+
+type Option func(*TweetAggregator)
+
+// NewTweetAggregator runs a tumbling window of duration unless WithWindows says otherwise
+func NewTweetAggregator(in <-chan *models.TweetEvent, sink storage.MetricsSink, duration time.Duration, opts ...Option) *TweetAggregator {
+	t := &TweetAggregator{
+		Windows:         []WindowSpec{TumblingWindow(duration)},
+		WatermarkDelay:  2 * time.Second,
+		AllowedLateness: 2 * duration,
+		IdleTimeout:     5 * time.Second,
+		Anomaly:         anomaly.DefaultConfig(),
+		Trending:        trending.NewTracker(trending.DefaultConfig()),
+		TopHashtags:     5,
+		TopTrending:     10,
+		settings:        make(chan Settings, 1),
+		done:            make(chan struct{}),
+		InChan:          in,
+		Sink:            sink,
+	}
+	for _, opt := range opts {
+		opt(t)
+	}
+
+	for _, spec := range t.Windows {
+		set := newWindowSet(spec)
+		if spec.Kind != Session {
+			set.detector = anomaly.NewDetector(t.Anomaly)
+		}
+		t.sets = append(t.sets, set)
+	}
+	return t
+}
+
+// WithTrending sets the horizons and capacity of the hashtag trend tracker
+func WithTrending(cfg trending.Config) Option {
+	return func(t *TweetAggregator) {
+		t.Trending = trending.NewTracker(cfg)
+	}
+}
+
+// WithTopN sets how many hashtags the window and trending rankings keep
+func WithTopN(hashtags, trending int) Option {
+	return func(t *TweetAggregator) {
+		if hashtags > 0 {
+			t.TopHashtags = hashtags
+		}
+		if trending > 0 {
+			t.TopTrending = trending
+		}
+	}
+}
+
+// WithAnomalyConfig sets how every window series looks for anomalies
+func WithAnomalyConfig(cfg anomaly.Config) Option {
+	return func(t *TweetAggregator) {
+		t.Anomaly = cfg
+	}
 }
 
-func NewTweetAggregator(in <-chan *models.Tweet, writer *storage.InfluxWriter, duration time.Duration) *TweetAggregator {
-	return &TweetAggregator{
-		Batch:          make([]*models.Tweet, 0, 100), // preallocate some space
-		WindowDuration: duration,
-		InChan:         in,
-		InfluxWriter:   writer,
+// WithWindows replaces the default tumbling window with specs
+func WithWindows(specs ...WindowSpec) Option {
+	return func(t *TweetAggregator) {
+		if len(specs) > 0 {
+			t.Windows = specs
+		}
+	}
+}
+
+// WithWatermarkDelay sets how long the aggregator waits for out of order events
+func WithWatermarkDelay(delay time.Duration) Option {
+	return func(t *TweetAggregator) {
+		if delay >= 0 {
+			t.WatermarkDelay = delay
+		}
+	}
+}
+
+// WithAllowedLateness keeps closed windows open to late events for lateness
+func WithAllowedLateness(lateness time.Duration) Option {
+	return func(t *TweetAggregator) {
+		if lateness >= 0 {
+			t.AllowedLateness = lateness
+		}
+	}
+}
+
+// WithIdleTimeout lets the watermark move on after timeout without events
+// so the last windows of a stream that went quiet are still emitted
+func WithIdleTimeout(timeout time.Duration) Option {
+	return func(t *TweetAggregator) {
+		if timeout >= 0 {
+			t.IdleTimeout = timeout
+		}
+	}
+}
+
+// WithEmitTime windows events by when they were emitted
+// seeded runs use it, their tweet timestamps advance per clock reading instead of with time
+func WithEmitTime() Option {
+	return func(t *TweetAggregator) {
+		t.EmitTime = true
 	}
 }
 
 // Start begins the aggregation window loop
+// when ctx is done the events already queued are added and every open window,
+// including the last partial one, is emitted before Done is closed
 func (t *TweetAggregator) Start(ctx context.Context) {
-	ticker := time.NewTicker(t.WindowDuration)
-	defer ticker.Stop()
+	defer close(t.done)
 
-	windowStart := time.Now()
+	ticker := time.NewTicker(t.fireInterval())
+	defer ticker.Stop()
 
 	for {
 		select {
 		case <-ctx.Done():
-			if len(t.Batch) > 0 {
-				t.processBatch(windowStart)
-			}
+			t.drain()
+			t.flush()
 			log.Println("Aggregator received context cancellation. Shutting down gracefully.")
 			return
 
-		case tweet, ok := <-t.InChan:
+		case event, ok := <-t.InChan:
 			if !ok {
-				if len(t.Batch) > 0 {
-					t.processBatch(windowStart)
-				}
+				t.flush()
 				return
 			}
-			t.Batch = append(t.Batch, tweet)
+			t.add(event)
+
+		case <-ticker.C:
+			t.fire()
+
+		case settings := <-t.settings:
+			t.applySettings(settings)
+		}
+	}
+}
+
+// Done is closed once Start has emitted its last windows
+func (t *TweetAggregator) Done() <-chan struct{} {
+	return t.done
+}
 
-		case windowEnd := <-ticker.C:
-			if len(t.Batch) > 0 {
-				t.processBatch(windowStart)
-			} else {
-				log.Println("window passed with zero tweets")
+// drain adds the events waiting in InChan without waiting for more
+func (t *TweetAggregator) drain() {
+	for {
+		select {
+		case event, ok := <-t.InChan:
+			if !ok {
+				return
 			}
-			t.Batch = t.Batch[:0] // reuse slice memory
-			windowStart = windowEnd
+			t.add(event)
+		default:
+			return
 		}
 	}
 }
 
-// processBatch calculates metrics
-// and writes them to InfluxDB
-func (t *TweetAggregator) processBatch(windowStart time.Time) {
-	metrics := models.WindowMetrics{
-		WindowStart: windowStart,
-		WindowEnd:   time.Now(),
-		TotalTweets: len(t.Batch),
-		MinLatency:  time.Hour,
-		MaxLatency:  0,
+// UpdateSettings hands new settings to the running aggregator
+// they apply from the next window emitted
+func (t *TweetAggregator) UpdateSettings(settings Settings) {
+	// keep only the latest update
+	select {
+	case <-t.settings:
+	default:
 	}
+	t.settings <- settings
+}
 
-	if metrics.TotalTweets == 0 {
-		return
+func (t *TweetAggregator) applySettings(settings Settings) {
+	WithTopN(settings.TopHashtags, settings.TopTrending)(t)
+	if settings.AnomalyThreshold > 0 {
+		t.Anomaly.Threshold = settings.AnomalyThreshold
+		for _, set := range t.sets {
+			if set.detector != nil {
+				set.detector.SetThreshold(settings.AnomalyThreshold)
+			}
+		}
+	}
+	log.Printf("Aggregator settings updated: TopHashtags=%d, TopTrending=%d, AnomalyThreshold=%.2f",
+		t.TopHashtags, t.TopTrending, t.Anomaly.Threshold)
+}
+
+// processWindow calculates metrics
+// and writes them to the metrics sink
+func (t *TweetAggregator) processWindow(set *windowSet, w *window) {
+	metrics := models.WindowMetrics{
+		Window:      set.spec.Name,
+		Key:         w.user,
+		WindowStart: w.start,
+		WindowEnd:   w.end,
+		TotalEvents: len(w.events),
+		Watermark:   t.watermark,
+		Revision:    w.revision,
+		LateEvents:  w.late,
+		LateDropped: set.lateDropped,
 	}
 
 	hashtagCounts := make(map[string]int)
-	var totalLatency time.Duration
+	users := make(map[string]*models.UserActivity)
 
-	for _, tweet := range t.Batch {
-		t.countHashtags(tweet, hashtagCounts)
-		t.EngagementStats(tweet, &metrics)
-		t.calculateVerifiedStatus(tweet, &metrics)
+	for _, event := range w.events {
+		switch event.Type {
+		case models.EventCreated:
+			metrics.TotalTweets++
+			t.countHashtags(event.Tweet, hashtagCounts)
+			t.EngagementStats(event.Tweet, &metrics)
+			t.calculateVerifiedStatus(event.Tweet, &metrics)
+			t.countUserActivity(event.Tweet, 1, event.Tweet.Engagement(), users)
+		case models.EventUpdated:
+			metrics.UpdatedTweets++
+			delta := t.engagementDelta(event, &metrics)
+			t.countUserActivity(event.Tweet, 0, delta, users)
+		case models.EventDeleted:
+			metrics.DeletedTweets++
+		}
+	}
 
-		latency := t.calculateLatency(tweet, &metrics)
-		totalLatency += latency
+	t.calculateLatency(w.latency, &metrics)
+	metrics.HashtagCounts = t.findTrendingHashtags(hashtagCounts, len(hashtagCounts))
+	metrics.TrendingHashtags = t.findTrendingHashtags(hashtagCounts, t.TopHashtags)
+	metrics.ActiveUsers = t.rankUsers(users)
+	if set.spec.Kind != Session {
+		metrics.TrendingNow = t.Trending.Trending(t.TopTrending, w.end)
 	}
+	t.detectAnomalies(set, w, &metrics)
 
-	metrics.AvgLatency = totalLatency / time.Duration(metrics.TotalTweets)
-	metrics.TrendingHashtags = t.findTrendingHashtags(hashtagCounts, 5)
-	metrics.IsAnomaly = t.detectAnomaly(&metrics)
+	if err := t.Sink.Insert(metrics); err != nil {
+		log.Println("Failed to write metrics:", err)
+	}
 
-	if err := t.InfluxWriter.Insert(metrics); err != nil {
-		log.Println("Failed to write metrics to InfluxDB:", err)
+	if set.spec.Kind == Session {
+		return // one line per user session would drown the log
 	}
 
-	log.Printf("Batch Processed: Tweets=%d, Engagement=%d, Anomaly=%t, AvgLatency=%s, MaxLatency=%s\n",
-		metrics.TotalTweets, metrics.TotalEngagement, metrics.IsAnomaly,
-		metrics.AvgLatency.Round(time.Millisecond), metrics.MaxLatency.Round(time.Millisecond))
+	log.Printf("Window Processed: Window=%s, Start=%s, Revision=%d, Events=%d, Created=%d, Updated=%d, Deleted=%d, Late=%d, LateDropped=%d, Engagement=%d, Anomaly=%t, AvgLatency=%s, P99Latency=%s, MaxLatency=%s\n",
+		metrics.Window, metrics.WindowStart.Format(time.RFC3339), metrics.Revision, metrics.TotalEvents, metrics.TotalTweets, metrics.UpdatedTweets, metrics.DeletedTweets,
+		metrics.LateEvents, metrics.LateDropped, metrics.TotalEngagement, metrics.IsAnomaly,
+		metrics.AvgLatency.Round(time.Millisecond), metrics.P99Latency.Round(time.Millisecond), metrics.MaxLatency.Round(time.Millisecond))
 }
 
 // countHashtags counts hashtags for a tweet
 func (t *TweetAggregator) countHashtags(tweet *models.Tweet, counts map[string]int) {
-	for _, tag := range tweet.HashTag {
+	for _, tag := range hashtags(tweet) {
 		counts[tag]++
 	}
 }
 
+// hashtags returns the normalized hashtags of a tweet
+// parsing them from the message when the producer did not extract them
+func hashtags(tweet *models.Tweet) []string {
+	tags := tweet.HashTag
+	if len(tags) == 0 {
+		tags = entities.Extract(tweet.Message).Hashtags
+	}
+
+	normalized := make([]string, len(tags))
+	for i, tag := range tags {
+		normalized[i] = entities.NormalizeHashtag(tag)
+	}
+	return normalized
+}
+
 // EngagementStats sums reactions and comments
 func (t *TweetAggregator) EngagementStats(tweet *models.Tweet, metrics *models.WindowMetrics) {
-	likes, retweets := 0, 0
-	for _, r := range tweet.Reactions {
-		switch r.ReactionType {
-		case "like":
-			likes += r.Count
-		case "retweet":
-			retweets += r.Count
+	metrics.TotalEngagement += tweet.Engagement()
+}
+
+// engagementDelta adds only the engagement an update brought in
+// so tweets edited many times in a window are not counted over and over
+func (t *TweetAggregator) engagementDelta(event *models.TweetEvent, metrics *models.WindowMetrics) int {
+	delta := event.Tweet.Engagement() - event.Previous.Engagement()
+	if delta <= 0 {
+		return 0
+	}
+	metrics.TotalEngagement += delta
+	return delta
+}
+
+// countUserActivity credits tweets and engagement to the author of the tweet
+func (t *TweetAggregator) countUserActivity(tweet *models.Tweet, tweets, engagement int, users map[string]*models.UserActivity) {
+	if tweet.User == nil || (tweets == 0 && engagement == 0) {
+		return
+	}
+
+	activity, ok := users[tweet.User.UserID]
+	if !ok {
+		activity = &models.UserActivity{
+			UserID: tweet.User.UserID,
+			Handle: tweet.User.Handle,
+			Status: tweet.User.Status,
 		}
+		users[tweet.User.UserID] = activity
 	}
-	metrics.TotalEngagement += likes + retweets + len(tweet.Comments)
+	activity.Tweets += tweets
+	activity.Engagement += engagement
+}
+
+// rankUsers orders users by tweets then engagement
+func (t *TweetAggregator) rankUsers(users map[string]*models.UserActivity) []models.UserActivity {
+	ranked := make([]models.UserActivity, 0, len(users))
+	for _, activity := range users {
+		ranked = append(ranked, *activity)
+	}
+
+	sort.Slice(ranked, func(i, j int) bool {
+		if ranked[i].Tweets != ranked[j].Tweets {
+			return ranked[i].Tweets > ranked[j].Tweets
+		}
+		if ranked[i].Engagement != ranked[j].Engagement {
+			return ranked[i].Engagement > ranked[j].Engagement
+		}
+		return ranked[i].UserID < ranked[j].UserID
+	})
+	return ranked
 }
 
 // calculateVerifiedStatus counts verified and unverified users
@@ -133,46 +367,42 @@ func (t *TweetAggregator) calculateVerifiedStatus(tweet *models.Tweet, metrics *
 	}
 }
 
-// calculateLatency updates min/max metrics and returns the latency
-func (t *TweetAggregator) calculateLatency(tweet *models.Tweet, metrics *models.WindowMetrics) time.Duration {
-	latency := time.Since(tweet.CreatedAt)
-	if latency > metrics.MaxLatency {
-		metrics.MaxLatency = latency
-	}
-	if latency < metrics.MinLatency {
-		metrics.MinLatency = latency
+// calculateLatency fills the latency summary and percentiles from the window sketch
+// latency is measured from the moment each event was emitted until it arrived
+// sinks get a copy of the sketch, late events keep adding to the window's own
+func (t *TweetAggregator) calculateLatency(latency *sketch.DDSketch, metrics *models.WindowMetrics) {
+	if latency.Count() == 0 {
+		return
 	}
-	return latency
-}
 
-// detectAnomaly checks thresholds for anomalies
-func (t *TweetAggregator) detectAnomaly(metrics *models.WindowMetrics) bool {
-	const (
-		TWEET_VOLUME_THRESHOLD     = 50
-		LATENCY_SPIKE_MULTIPLIER   = 3.0
-		ENGAGEMENT_BURST_THRESHOLD = 1000
-	)
+	metrics.MinLatency = time.Duration(latency.Min())
+	metrics.MaxLatency = time.Duration(latency.Max())
+	metrics.AvgLatency = time.Duration(latency.Mean())
+	metrics.P50Latency = time.Duration(latency.Quantile(0.50))
+	metrics.P90Latency = time.Duration(latency.Quantile(0.90))
+	metrics.P99Latency = time.Duration(latency.Quantile(0.99))
+	metrics.P999Latency = time.Duration(latency.Quantile(0.999))
+	metrics.LatencySketch = latency.Clone()
+}
 
-	if metrics.TotalTweets > TWEET_VOLUME_THRESHOLD {
-		log.Printf("Anomaly: High tweet volume (%d > %d)", metrics.TotalTweets, TWEET_VOLUME_THRESHOLD)
-		return true
+// detectAnomalies scores the window against the baselines of its window series
+// a re-emitted window is only checked so late data does not count twice
+func (t *TweetAggregator) detectAnomalies(set *windowSet, w *window, metrics *models.WindowMetrics) {
+	if set.detector == nil {
+		return
 	}
 
-	if metrics.TotalTweets > 0 {
-		avg := float64(metrics.AvgLatency.Milliseconds())
-		max := float64(metrics.MaxLatency.Milliseconds())
-		if max > avg*LATENCY_SPIKE_MULTIPLIER {
-			log.Printf("Anomaly: Latency spike (Max %s > %.1fx Avg %s)", metrics.MaxLatency.Round(time.Millisecond), LATENCY_SPIKE_MULTIPLIER, metrics.AvgLatency.Round(time.Millisecond))
-			return true
-		}
+	if w.revision == 0 {
+		metrics.Anomalies = set.detector.Observe(metrics)
+	} else {
+		metrics.Anomalies = set.detector.Check(metrics)
 	}
+	metrics.IsAnomaly = len(metrics.Anomalies) > 0
 
-	if metrics.TotalEngagement > ENGAGEMENT_BURST_THRESHOLD {
-		log.Printf("Anomaly: High engagement (%d > %d)", metrics.TotalEngagement, ENGAGEMENT_BURST_THRESHOLD)
-		return true
+	for _, a := range metrics.Anomalies {
+		log.Printf("Anomaly: %s %s %s in %s (%.1f, expected %.1f, score %.1f by %s)",
+			a.Severity, a.Metric, a.Direction, metrics.Window, a.Value, a.Expected, a.Score, a.Method)
 	}
-
-	return false
 }
 
 // findTrendingHashtags returns top trending hashtags
@@ -183,7 +413,10 @@ func (t *TweetAggregator) findTrendingHashtags(counts map[string]int, n int) []m
 	}
 
 	sort.Slice(tags, func(i, j int) bool {
-		return tags[i].Count > tags[j].Count
+		if tags[i].Count != tags[j].Count {
+			return tags[i].Count > tags[j].Count
+		}
+		return tags[i].Tag < tags[j].Tag
 	})
 
 	if len(tags) > n {
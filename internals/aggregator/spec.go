@@ -0,0 +1,172 @@
+package aggregator
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Window kinds
+const (
+	Tumbling = "tumbling" // fixed windows of size that do not overlap
+	Sliding  = "sliding"  // windows of size starting every slide, they overlap when slide < size
+	Session  = "session"  // per user windows that close after gap without activity
+)
+
+// WindowSpec defines one window strategy the aggregator runs
+type WindowSpec struct {
+	Name  string
+	Kind  string
+	Size  time.Duration // tumbling and sliding
+	Slide time.Duration // sliding
+	Gap   time.Duration // session
+}
+
+func TumblingWindow(size time.Duration) WindowSpec {
+	return WindowSpec{Kind: Tumbling, Size: size}.named()
+}
+
+func SlidingWindow(size, slide time.Duration) WindowSpec {
+	return WindowSpec{Kind: Sliding, Size: size, Slide: slide}.named()
+}
+
+func SessionWindow(gap time.Duration) WindowSpec {
+	return WindowSpec{Kind: Session, Gap: gap}.named()
+}
+
+// named fills in a default name like sliding_5m_5s
+func (s WindowSpec) named() WindowSpec {
+	if s.Name != "" {
+		return s
+	}
+	switch s.Kind {
+	case Tumbling:
+		s.Name = Tumbling + "_" + shortDuration(s.Size)
+	case Sliding:
+		s.Name = Sliding + "_" + shortDuration(s.Size) + "_" + shortDuration(s.Slide)
+	case Session:
+		s.Name = Session + "_" + shortDuration(s.Gap)
+	}
+	return s
+}
+
+// Validate checks the durations the kind relies on
+func (s WindowSpec) Validate() error {
+	switch s.Kind {
+	case Tumbling:
+		if s.Size <= 0 {
+			return fmt.Errorf("window %s: size must be positive", s.Name)
+		}
+	case Sliding:
+		if s.Size <= 0 || s.Slide <= 0 || s.Slide > s.Size {
+			return fmt.Errorf("window %s: needs 0 < slide <= size", s.Name)
+		}
+	case Session:
+		if s.Gap <= 0 {
+			return fmt.Errorf("window %s: gap must be positive", s.Name)
+		}
+	default:
+		return fmt.Errorf("window %s: unknown kind %q", s.Name, s.Kind)
+	}
+	return nil
+}
+
+// step is the smallest interval at which windows of this spec close
+func (s WindowSpec) step() time.Duration {
+	switch s.Kind {
+	case Sliding:
+		return s.Slide
+	case Session:
+		return s.Gap
+	}
+	return s.Size
+}
+
+// ParseWindowSpecs parses a comma separated list of window definitions
+// tumbling:5s, sliding:5m/5s (size/slide) and session:30s (gap)
+func ParseWindowSpecs(value string) ([]WindowSpec, error) {
+	var specs []WindowSpec
+	var errs []error
+	names := make(map[string]struct{})
+
+	for _, def := range strings.Split(value, ",") {
+		def = strings.TrimSpace(def)
+		if def == "" {
+			continue
+		}
+
+		spec, err := parseWindowSpec(def)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if _, dup := names[spec.Name]; dup {
+			errs = append(errs, fmt.Errorf("window %s is defined twice", spec.Name))
+			continue
+		}
+		names[spec.Name] = struct{}{}
+		specs = append(specs, spec)
+	}
+
+	if len(specs) == 0 && len(errs) == 0 {
+		return nil, errors.New("no windows defined")
+	}
+	return specs, errors.Join(errs...)
+}
+
+func parseWindowSpec(def string) (WindowSpec, error) {
+	kind, args, ok := strings.Cut(def, ":")
+	if !ok {
+		return WindowSpec{}, fmt.Errorf("window %q: expected kind:duration", def)
+	}
+
+	var spec WindowSpec
+	switch strings.ToLower(strings.TrimSpace(kind)) {
+	case Tumbling:
+		size, err := time.ParseDuration(args)
+		if err != nil {
+			return WindowSpec{}, fmt.Errorf("window %q: %w", def, err)
+		}
+		spec = TumblingWindow(size)
+
+	case Sliding:
+		sizeArg, slideArg, ok := strings.Cut(args, "/")
+		if !ok {
+			return WindowSpec{}, fmt.Errorf("window %q: expected sliding:size/slide", def)
+		}
+		size, err := time.ParseDuration(sizeArg)
+		if err != nil {
+			return WindowSpec{}, fmt.Errorf("window %q: %w", def, err)
+		}
+		slide, err := time.ParseDuration(slideArg)
+		if err != nil {
+			return WindowSpec{}, fmt.Errorf("window %q: %w", def, err)
+		}
+		spec = SlidingWindow(size, slide)
+
+	case Session:
+		gap, err := time.ParseDuration(args)
+		if err != nil {
+			return WindowSpec{}, fmt.Errorf("window %q: %w", def, err)
+		}
+		spec = SessionWindow(gap)
+
+	default:
+		return WindowSpec{}, fmt.Errorf("window %q: unknown kind %q", def, kind)
+	}
+
+	return spec, spec.Validate()
+}
+
+// shortDuration drops the zero units time.Duration prints, 5m0s becomes 5m
+func shortDuration(d time.Duration) string {
+	s := d.String()
+	if strings.HasSuffix(s, "m0s") {
+		s = strings.TrimSuffix(s, "0s")
+	}
+	if strings.HasSuffix(s, "h0m") {
+		s = strings.TrimSuffix(s, "0m")
+	}
+	return s
+}
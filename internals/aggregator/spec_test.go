@@ -0,0 +1,58 @@
+package aggregator
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseWindowSpecs(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    []WindowSpec
+		wantErr bool
+	}{
+		{value: "tumbling:5s", want: []WindowSpec{{Name: "tumbling_5s", Kind: Tumbling, Size: 5 * time.Second}}},
+		{value: "sliding:5m/5s", want: []WindowSpec{{Name: "sliding_5m_5s", Kind: Sliding, Size: 5 * time.Minute, Slide: 5 * time.Second}}},
+		{value: "session:30s", want: []WindowSpec{{Name: "session_30s", Kind: Session, Gap: 30 * time.Second}}},
+		{value: " Tumbling:1m , session:1h ,", want: []WindowSpec{
+			{Name: "tumbling_1m", Kind: Tumbling, Size: time.Minute},
+			{Name: "session_1h", Kind: Session, Gap: time.Hour},
+		}},
+		{value: "sliding:10s/10s", want: []WindowSpec{{Name: "sliding_10s_10s", Kind: Sliding, Size: 10 * time.Second, Slide: 10 * time.Second}}},
+		{value: "", wantErr: true},
+		{value: " , ", wantErr: true},
+		{value: "tumbling", wantErr: true},
+		{value: "tumbling:abc", wantErr: true},
+		{value: "tumbling:-5s", wantErr: true},
+		{value: "sliding:5s", wantErr: true},
+		{value: "sliding:5s/10s", wantErr: true},
+		{value: "sliding:5s/0s", wantErr: true},
+		{value: "session:0s", wantErr: true},
+		{value: "hopping:5s", wantErr: true},
+		{value: "tumbling:5s,tumbling:5s", wantErr: true},
+		{value: "tumbling:5s,session:oops", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := ParseWindowSpecs(tt.value)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("got %+v, want an error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %+v, want %+v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("spec %d = %+v, want %+v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
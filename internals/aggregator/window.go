@@ -0,0 +1,316 @@
+package aggregator
+
+import (
+	"log"
+	"sort"
+	"time"
+
+	"github.com/Udehlee/tweet-stream/internals/anomaly"
+	"github.com/Udehlee/tweet-stream/internals/sketch"
+	"github.com/Udehlee/tweet-stream/models"
+)
+
+// window is one event time window of a spec
+// user is only set for session windows
+// revision counts how many times its result has been emitted
+type window struct {
+	user       string
+	start, end time.Time
+	events     []*models.TweetEvent
+	latency    *sketch.DDSketch // nanoseconds from emit to arrival
+	late       int              // events that arrived after the window first closed
+	emitted    bool             // the on time result has been written
+	dirty      bool             // late events arrived since the last emission
+	revision   int
+}
+
+func newWindow(user string, start, end time.Time) *window {
+	return &window{
+		user:    user,
+		start:   start,
+		end:     end,
+		latency: sketch.New(sketch.DefaultRelativeAccuracy),
+	}
+}
+
+type windowKey struct {
+	user  string
+	start time.Time
+}
+
+// maxGapWindows caps the empty windows emitted for a gap in the stream
+const maxGapWindows = 100
+
+// windowSet holds the open windows of a single spec
+// and the anomaly baselines of its window series
+type windowSet struct {
+	spec        WindowSpec
+	windows     map[windowKey]*window
+	detector    *anomaly.Detector
+	nextStart   time.Time // start of the window expected to close next
+	lateDropped int
+}
+
+func newWindowSet(spec WindowSpec) *windowSet {
+	return &windowSet{
+		spec:    spec,
+		windows: make(map[windowKey]*window),
+	}
+}
+
+// eventTime is when the change happened according to the producer
+// creations use CreatedAt, updates UpdatedAt, anything else the emit time
+func (t *TweetAggregator) eventTime(event *models.TweetEvent) time.Time {
+	var ts time.Time
+	if event.Tweet != nil && !t.EmitTime {
+		switch event.Type {
+		case models.EventCreated:
+			ts = event.Tweet.CreatedAt
+		case models.EventUpdated:
+			ts = event.Tweet.UpdatedAt
+		}
+	}
+	if ts.IsZero() {
+		ts = event.EmittedAt
+	}
+	return ts
+}
+
+// add assigns the event to its windows in every spec and advances the watermark
+// a spec drops the event when all of its windows are past the allowed lateness
+func (t *TweetAggregator) add(event *models.TweetEvent) {
+	ts := t.eventTime(event)
+	latency := time.Since(event.EmittedAt)
+
+	if event.Type == models.EventCreated && event.Tweet != nil {
+		for _, tag := range hashtags(event.Tweet) {
+			t.Trending.Observe(tag, ts)
+		}
+	}
+
+	for _, set := range t.sets {
+		var accepted bool
+		if set.spec.Kind == Session {
+			accepted = t.addToSession(set, event, ts, latency)
+		} else {
+			accepted = t.addToFixed(set, event, ts, latency)
+		}
+
+		if !accepted {
+			set.lateDropped++
+			log.Printf("Dropped late event %s from %s: event time %s is past the allowed lateness (watermark %s)",
+				event.EventID, set.spec.Name, ts.Format(time.RFC3339Nano), t.watermark.Format(time.RFC3339Nano))
+		}
+	}
+
+	t.arrived = time.Now()
+	if ts.After(t.newest) {
+		t.newest = ts
+	}
+	if wm := ts.Add(-t.WatermarkDelay); wm.After(t.watermark) {
+		t.watermark = wm
+	}
+}
+
+// advanceIdle moves the watermark while no events arrive
+// once the stream has been quiet for IdleTimeout event time is taken to have
+// moved on by as much wall time as passed since the last event arrived
+func (t *TweetAggregator) advanceIdle(now time.Time) {
+	if t.IdleTimeout <= 0 || t.arrived.IsZero() {
+		return
+	}
+
+	idle := now.Sub(t.arrived)
+	if idle < t.IdleTimeout {
+		return
+	}
+	if wm := t.newest.Add(idle - t.WatermarkDelay); wm.After(t.watermark) {
+		t.watermark = wm
+	}
+}
+
+// addToFixed adds the event to every tumbling or sliding window covering ts
+func (t *TweetAggregator) addToFixed(set *windowSet, event *models.TweetEvent, ts time.Time, latency time.Duration) bool {
+	size, slide := set.spec.Size, set.spec.Slide
+	if set.spec.Kind == Tumbling {
+		slide = size
+	}
+
+	var accepted bool
+	for start := ts.Truncate(slide); start.After(ts.Add(-size)); start = start.Add(-slide) {
+		end := start.Add(size)
+		if t.expired(end) {
+			continue
+		}
+
+		key := windowKey{start: start}
+		w, ok := set.windows[key]
+		if !ok {
+			w = newWindow("", start, end)
+			set.windows[key] = w
+		}
+		w.append(event, latency)
+		accepted = true
+	}
+	return accepted
+}
+
+// addToSession extends the author's session around ts, merging sessions
+// the event bridges, or starts a new one
+func (t *TweetAggregator) addToSession(set *windowSet, event *models.TweetEvent, ts time.Time, latency time.Duration) bool {
+	gap := set.spec.Gap
+	var user string
+	if event.Tweet != nil && event.Tweet.User != nil {
+		user = event.Tweet.User.UserID
+	}
+
+	var overlapping []*window
+	for key, w := range set.windows {
+		if key.user == user && !ts.Before(w.start.Add(-gap)) && ts.Before(w.end) {
+			overlapping = append(overlapping, w)
+		}
+	}
+
+	if len(overlapping) == 0 {
+		if t.expired(ts.Add(gap)) {
+			return false
+		}
+		w := newWindow(user, ts, ts.Add(gap))
+		w.append(event, latency)
+		set.windows[windowKey{user: user, start: ts}] = w
+		return true
+	}
+
+	sort.Slice(overlapping, func(i, j int) bool {
+		return overlapping[i].start.Before(overlapping[j].start)
+	})
+
+	session := overlapping[0]
+	delete(set.windows, windowKey{user: user, start: session.start})
+	for _, w := range overlapping[1:] {
+		delete(set.windows, windowKey{user: user, start: w.start})
+		session.events = append(session.events, w.events...)
+		session.latency.Merge(w.latency)
+		session.late += w.late
+		session.emitted = session.emitted || w.emitted
+		session.revision = max(session.revision, w.revision)
+		if w.end.After(session.end) {
+			session.end = w.end
+		}
+	}
+
+	if ts.Before(session.start) {
+		session.start = ts
+	}
+	if end := ts.Add(gap); end.After(session.end) {
+		session.end = end
+	}
+	session.append(event, latency)
+	set.windows[windowKey{user: user, start: session.start}] = session
+	return true
+}
+
+// append adds the event, flagging the window for another emission when it already closed
+func (w *window) append(event *models.TweetEvent, latency time.Duration) {
+	w.events = append(w.events, event)
+	w.latency.Add(float64(latency))
+	if w.emitted {
+		w.late++
+		w.dirty = true
+	}
+}
+
+// expired reports whether a window ending at end no longer accepts events
+func (t *TweetAggregator) expired(end time.Time) bool {
+	return !t.watermark.IsZero() && !end.Add(t.AllowedLateness).After(t.watermark)
+}
+
+// fire emits windows the watermark has passed, re-emits windows
+// that received late events and forgets windows past their allowed lateness
+func (t *TweetAggregator) fire() {
+	t.advanceIdle(time.Now())
+
+	for _, set := range t.sets {
+		for _, w := range set.sorted() {
+			if !w.end.After(t.watermark) && (!w.emitted || w.dirty) {
+				if !w.emitted {
+					t.fillGap(set, w.start)
+				}
+				t.emit(set, w)
+			}
+			if t.expired(w.end) {
+				delete(set.windows, windowKey{user: w.user, start: w.start})
+			}
+		}
+	}
+}
+
+// fillGap emits empty windows for the steps before start that saw no events
+// so dashboards and anomaly baselines see the drop instead of a hole
+func (t *TweetAggregator) fillGap(set *windowSet, start time.Time) {
+	if set.spec.Kind == Session {
+		return
+	}
+
+	step := set.spec.step()
+	if !set.nextStart.IsZero() {
+		for st, n := set.nextStart, 0; st.Before(start) && n < maxGapWindows; st, n = st.Add(step), n+1 {
+			key := windowKey{start: st}
+			if _, ok := set.windows[key]; ok {
+				continue
+			}
+			w := newWindow("", st, st.Add(set.spec.Size))
+			set.windows[key] = w
+			t.emit(set, w)
+		}
+	}
+
+	if next := start.Add(step); next.After(set.nextStart) {
+		set.nextStart = next
+	}
+}
+
+// flush emits every window that has unwritten events, used on shutdown
+func (t *TweetAggregator) flush() {
+	for _, set := range t.sets {
+		for _, w := range set.sorted() {
+			if !w.emitted || w.dirty {
+				t.emit(set, w)
+			}
+		}
+		set.windows = make(map[windowKey]*window)
+	}
+}
+
+func (t *TweetAggregator) emit(set *windowSet, w *window) {
+	if w.emitted {
+		w.revision++
+	}
+	t.processWindow(set, w)
+	w.emitted = true
+	w.dirty = false
+}
+
+// sorted returns the open windows oldest first
+func (s *windowSet) sorted() []*window {
+	windows := make([]*window, 0, len(s.windows))
+	for _, w := range s.windows {
+		windows = append(windows, w)
+	}
+	sort.Slice(windows, func(i, j int) bool {
+		if !windows[i].start.Equal(windows[j].start) {
+			return windows[i].start.Before(windows[j].start)
+		}
+		return windows[i].user < windows[j].user
+	})
+	return windows
+}
+
+// fireInterval is how often closed windows are checked for
+func (t *TweetAggregator) fireInterval() time.Duration {
+	interval := time.Second
+	for _, set := range t.sets {
+		interval = min(interval, set.spec.step()/5)
+	}
+	return max(interval, 100*time.Millisecond)
+}
@@ -0,0 +1,203 @@
+package aggregator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Udehlee/tweet-stream/internals/storage"
+	"github.com/Udehlee/tweet-stream/models"
+)
+
+var epoch = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// newTestAggregator runs a 10s tumbling window with a 2s watermark delay
+// and 10s of allowed lateness, the idle advance is off unless opts turn it on
+func newTestAggregator(opts ...Option) (*TweetAggregator, *storage.MemorySink) {
+	sink := storage.NewMemorySink()
+	opts = append([]Option{
+		WithWatermarkDelay(2 * time.Second),
+		WithAllowedLateness(10 * time.Second),
+		WithIdleTimeout(0),
+	}, opts...)
+	return NewTweetAggregator(nil, sink, 10*time.Second, opts...), sink
+}
+
+// created is a CREATED event by user whose tweet was posted at offset past epoch
+func created(id, user string, offset time.Duration) *models.TweetEvent {
+	return &models.TweetEvent{
+		EventID: id,
+		Type:    models.EventCreated,
+		Tweet: &models.Tweet{
+			ID:        id,
+			User:      &models.User{UserID: user},
+			CreatedAt: epoch.Add(offset),
+		},
+		EmittedAt: time.Now(),
+	}
+}
+
+// windowsStarting returns the emitted results of the window starting at offset past epoch
+func windowsStarting(sink *storage.MemorySink, offset time.Duration) []models.WindowMetrics {
+	var found []models.WindowMetrics
+	for _, m := range sink.Metrics() {
+		if m.WindowStart.Equal(epoch.Add(offset)) {
+			found = append(found, m)
+		}
+	}
+	return found
+}
+
+func TestOnTimeEventsCloseTheirWindow(t *testing.T) {
+	agg, sink := newTestAggregator()
+
+	agg.add(created("a", "u1", time.Second))
+	agg.add(created("b", "u2", 3*time.Second))
+	agg.fire()
+	if got := len(sink.Metrics()); got != 0 {
+		t.Fatalf("%d windows emitted before the watermark passed them", got)
+	}
+
+	// the watermark moves to 11s, past the end of the first window only
+	agg.add(created("c", "u1", 13*time.Second))
+	agg.fire()
+
+	first := windowsStarting(sink, 0)
+	if len(first) != 1 {
+		t.Fatalf("first window emitted %d times, want 1", len(first))
+	}
+	if m := first[0]; m.TotalEvents != 2 || m.TotalTweets != 2 || m.Revision != 0 || m.LateEvents != 0 {
+		t.Errorf("first window = %d events, %d tweets, revision %d, %d late; want 2, 2, 0, 0",
+			m.TotalEvents, m.TotalTweets, m.Revision, m.LateEvents)
+	}
+	if n := len(windowsStarting(sink, 10*time.Second)); n != 0 {
+		t.Errorf("second window emitted %d times before it closed", n)
+	}
+}
+
+func TestLateEventWithinLatenessRevisesWindow(t *testing.T) {
+	agg, sink := newTestAggregator()
+
+	agg.add(created("a", "u1", time.Second))
+	agg.add(created("b", "u1", 13*time.Second))
+	agg.fire()
+
+	// the first window closed at 10s but stays open for late events until 20s
+	agg.add(created("late", "u2", 5*time.Second))
+	agg.fire()
+
+	results := windowsStarting(sink, 0)
+	if len(results) != 2 {
+		t.Fatalf("first window emitted %d times, want 2", len(results))
+	}
+	revised := results[1]
+	if revised.Revision != 1 || revised.TotalEvents != 2 || revised.LateEvents != 1 {
+		t.Errorf("revision = %d with %d events, %d late; want 1 with 2 events, 1 late",
+			revised.Revision, revised.TotalEvents, revised.LateEvents)
+	}
+	if revised.LatencySketch == nil || revised.LatencySketch.Count() != 2 {
+		t.Errorf("revised latency sketch does not hold both events")
+	}
+	if n := results[0].LatencySketch.Count(); n != 1 {
+		t.Errorf("sketch of the first result holds %d latencies after the revision, want 1", n)
+	}
+
+	// a fire without new late events does not emit again
+	agg.fire()
+	if n := len(windowsStarting(sink, 0)); n != 2 {
+		t.Errorf("first window emitted %d times after a quiet fire, want 2", n)
+	}
+}
+
+func TestEventPastLatenessIsDropped(t *testing.T) {
+	agg, sink := newTestAggregator()
+
+	agg.add(created("a", "u1", time.Second))
+	// the watermark moves to 30s, the first window is past 10s end + 10s lateness
+	agg.add(created("b", "u1", 32*time.Second))
+	agg.fire()
+
+	agg.add(created("too-late", "u2", 4*time.Second))
+	agg.fire()
+	if n := len(windowsStarting(sink, 0)); n != 1 {
+		t.Fatalf("first window emitted %d times, want only the on time result", n)
+	}
+
+	agg.add(created("c", "u1", 45*time.Second))
+	agg.fire()
+	results := windowsStarting(sink, 30*time.Second)
+	if len(results) != 1 {
+		t.Fatalf("window at 30s emitted %d times, want 1", len(results))
+	}
+	if dropped := results[0].LateDropped; dropped != 1 {
+		t.Errorf("late dropped = %d, want 1", dropped)
+	}
+	// the window at 20s saw nothing and is emitted empty instead of left out
+	if empty := windowsStarting(sink, 20*time.Second); len(empty) != 1 || empty[0].TotalEvents != 0 {
+		t.Errorf("window at 20s = %+v, want one empty result", empty)
+	}
+}
+
+func TestIdleStreamStillClosesLastWindow(t *testing.T) {
+	agg, sink := newTestAggregator(WithIdleTimeout(5 * time.Second))
+
+	agg.add(created("a", "u1", time.Second))
+	arrived := agg.arrived
+
+	agg.advanceIdle(arrived.Add(3 * time.Second))
+	agg.fire()
+	if n := len(sink.Metrics()); n != 0 {
+		t.Fatalf("%d windows emitted before the idle timeout", n)
+	}
+
+	// 12s after the event the watermark is at 1s + 12s - 2s = 11s
+	agg.advanceIdle(arrived.Add(12 * time.Second))
+	agg.fire()
+	if results := windowsStarting(sink, 0); len(results) != 1 || results[0].TotalEvents != 1 {
+		t.Errorf("last window = %+v, want one result with the event", results)
+	}
+}
+
+func TestSessionsMergeAndCloseAfterGap(t *testing.T) {
+	agg, sink := newTestAggregator(WithWindows(SessionWindow(10 * time.Second)))
+
+	agg.add(created("a1", "u1", 0))
+	agg.add(created("a2", "u1", 5*time.Second)) // within the gap, extends to 15s
+	agg.add(created("b1", "u2", 2*time.Second)) // another user, another session
+	agg.add(created("a3", "u1", 30*time.Second))
+	agg.add(created("c1", "u3", 40*time.Second))
+	agg.add(created("c2", "u3", 55*time.Second))
+	agg.add(created("c3", "u3", 47*time.Second)) // bridges [40s,50s) and [55s,65s)
+	agg.add(created("d1", "u4", 100*time.Second))
+	agg.fire()
+
+	type session struct {
+		user       string
+		start, end time.Duration
+		events     int
+	}
+	want := []session{
+		{user: "u1", start: 0, end: 15 * time.Second, events: 2},
+		{user: "u2", start: 2 * time.Second, end: 12 * time.Second, events: 1},
+		{user: "u1", start: 30 * time.Second, end: 40 * time.Second, events: 1},
+		{user: "u3", start: 40 * time.Second, end: 65 * time.Second, events: 3},
+	}
+
+	got := sink.Metrics()
+	if len(got) != len(want) {
+		t.Fatalf("emitted %d sessions, want %d: %+v", len(got), len(want), got)
+	}
+	for i, w := range want {
+		m := got[i]
+		if m.Key != w.user || !m.WindowStart.Equal(epoch.Add(w.start)) || !m.WindowEnd.Equal(epoch.Add(w.end)) || m.TotalEvents != w.events {
+			t.Errorf("session %d = %s [%s, %s) with %d events, want %s [%s, %s) with %d",
+				i, m.Key, m.WindowStart.Sub(epoch), m.WindowEnd.Sub(epoch), m.TotalEvents,
+				w.user, w.start, w.end, w.events)
+		}
+	}
+
+	// the open session is emitted on shutdown
+	agg.flush()
+	if last := sink.Metrics()[len(sink.Metrics())-1]; last.Key != "u4" || last.TotalEvents != 1 {
+		t.Errorf("flushed session = %s with %d events, want u4 with 1", last.Key, last.TotalEvents)
+	}
+}
@@ -0,0 +1,285 @@
+// Package anomaly keeps rolling baselines of window metrics and flags
+// values that stray too far from them in either direction
+package anomaly
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Udehlee/tweet-stream/models"
+)
+
+// Baseline methods
+const (
+	EWMA        = "ewma"         // exponentially weighted mean and variance, z-score
+	MAD         = "mad"          // median absolute deviation over recent windows, robust z-score
+	HoltWinters = "holt_winters" // additive seasonal forecast, residual z-score
+)
+
+// Metric names the detector understands
+const (
+	MetricEvents     = "total_events"
+	MetricTweets     = "total_tweets"
+	MetricUpdates    = "updated_tweets"
+	MetricDeletes    = "deleted_tweets"
+	MetricEngagement = "total_engagement"
+	MetricP99Latency = "p99_latency_ms"
+)
+
+// minDeviation is the smallest deviation a baseline reports
+// every metric is a count or milliseconds, so a difference of one is never news
+const minDeviation = 1.0
+
+var DefaultMetrics = []string{MetricEvents, MetricTweets, MetricEngagement, MetricP99Latency}
+
+// Severity levels, the score is compared against multiples of the threshold
+const (
+	SeverityMinor    = "minor"    // past the threshold
+	SeverityMajor    = "major"    // past 1.5x the threshold
+	SeverityCritical = "critical" // past 2x the threshold
+)
+
+type Config struct {
+	Method       string
+	Metrics      []string
+	Threshold    float64 // score at which a value is anomalous
+	Warmup       int     // observations before a baseline may flag anything
+	Alpha        float64 // EWMA and Holt-Winters level smoothing
+	Beta         float64 // Holt-Winters trend smoothing
+	GammaSeason  float64 // Holt-Winters seasonal smoothing
+	SeasonLength int     // Holt-Winters observations per season
+	WindowSize   int     // MAD observations kept
+}
+
+func DefaultConfig() Config {
+	return Config{
+		Method:       EWMA,
+		Metrics:      DefaultMetrics,
+		Threshold:    3.5,
+		Warmup:       12,
+		Alpha:        0.1,
+		Beta:         0.01,
+		GammaSeason:  0.1,
+		SeasonLength: 12,
+		WindowSize:   60,
+	}
+}
+
+// Validate checks the method and its parameters
+func (c Config) Validate() error {
+	switch c.Method {
+	case EWMA, MAD:
+	case HoltWinters:
+		if c.SeasonLength < 2 {
+			return fmt.Errorf("holt_winters needs a season length of at least 2")
+		}
+	default:
+		return fmt.Errorf("unknown anomaly method %q", c.Method)
+	}
+	if c.Threshold <= 0 {
+		return fmt.Errorf("anomaly threshold must be positive")
+	}
+	for _, m := range c.Metrics {
+		if !knownMetric(m) {
+			return fmt.Errorf("unknown anomaly metric %q", m)
+		}
+	}
+	return nil
+}
+
+// baseline predicts the next value of a single metric
+type baseline interface {
+	// expect returns the forecast and the typical deviation around it
+	expect() (expected, deviation float64)
+	update(x float64)
+	observations() int
+}
+
+// Detector holds one baseline per metric of a single window series
+type Detector struct {
+	cfg       Config
+	baselines map[string]baseline
+}
+
+func NewDetector(cfg Config) *Detector {
+	def := DefaultConfig()
+	if cfg.Method == "" {
+		cfg.Method = def.Method
+	}
+	if len(cfg.Metrics) == 0 {
+		cfg.Metrics = def.Metrics
+	}
+	if cfg.Threshold <= 0 {
+		cfg.Threshold = def.Threshold
+	}
+	if cfg.Warmup <= 0 {
+		cfg.Warmup = def.Warmup
+	}
+	if cfg.Alpha <= 0 || cfg.Alpha >= 1 {
+		cfg.Alpha = def.Alpha
+	}
+	if cfg.Beta <= 0 || cfg.Beta >= 1 {
+		cfg.Beta = def.Beta
+	}
+	if cfg.GammaSeason <= 0 || cfg.GammaSeason >= 1 {
+		cfg.GammaSeason = def.GammaSeason
+	}
+	if cfg.SeasonLength < 2 {
+		cfg.SeasonLength = def.SeasonLength
+	}
+	if cfg.WindowSize <= 0 {
+		cfg.WindowSize = def.WindowSize
+	}
+	if cfg.Method == HoltWinters && cfg.Warmup < 2*cfg.SeasonLength {
+		// the first season only seeds the seasonal terms
+		cfg.Warmup = 2 * cfg.SeasonLength
+	}
+
+	d := &Detector{
+		cfg:       cfg,
+		baselines: make(map[string]baseline, len(cfg.Metrics)),
+	}
+	for _, m := range cfg.Metrics {
+		d.baselines[m] = d.newBaseline()
+	}
+	return d
+}
+
+// SetThreshold changes the score at which values are anomalous, baselines are kept
+func (d *Detector) SetThreshold(threshold float64) {
+	if threshold > 0 {
+		d.cfg.Threshold = threshold
+	}
+}
+
+func (d *Detector) newBaseline() baseline {
+	switch d.cfg.Method {
+	case MAD:
+		return newMADBaseline(d.cfg.WindowSize)
+	case HoltWinters:
+		return newHoltWintersBaseline(d.cfg.Alpha, d.cfg.Beta, d.cfg.GammaSeason, d.cfg.SeasonLength)
+	}
+	return newEWMABaseline(d.cfg.Alpha)
+}
+
+// Observe scores the window against the baselines and then folds it in
+// anomalous values are clipped to the threshold first so a single
+// outlier cannot blow up the baseline, a lasting shift still moves it
+func (d *Detector) Observe(metrics *models.WindowMetrics) []models.Anomaly {
+	anomalies := d.Check(metrics)
+	values := Values(metrics)
+
+	for _, m := range d.cfg.Metrics {
+		value, ok := values[m]
+		if !ok {
+			continue
+		}
+
+		b := d.baselines[m]
+		if b.observations() >= d.cfg.Warmup {
+			expected, deviation := d.expect(b)
+			limit := d.cfg.Threshold * deviation
+			value = math.Max(expected-limit, math.Min(expected+limit, value))
+		}
+		b.update(value)
+	}
+	return anomalies
+}
+
+// expect returns the baseline forecast with a floor on the deviation
+// a flat history would otherwise make any change infinitely anomalous
+func (d *Detector) expect(b baseline) (float64, float64) {
+	expected, deviation := b.expect()
+	return expected, math.Max(deviation, math.Max(0.05*math.Abs(expected), minDeviation))
+}
+
+// Check scores the window without changing the baselines
+// re-emitted windows use it so they are not counted twice
+func (d *Detector) Check(metrics *models.WindowMetrics) []models.Anomaly {
+	values := Values(metrics)
+
+	var anomalies []models.Anomaly
+	for _, m := range d.cfg.Metrics {
+		b := d.baselines[m]
+		if b.observations() < d.cfg.Warmup {
+			continue
+		}
+
+		value, ok := values[m]
+		if !ok {
+			continue
+		}
+		expected, deviation := d.expect(b)
+
+		score := (value - expected) / deviation
+		if math.Abs(score) < d.cfg.Threshold {
+			continue
+		}
+
+		direction := models.AnomalySpike
+		if score < 0 {
+			direction = models.AnomalyDrop
+		}
+
+		anomalies = append(anomalies, models.Anomaly{
+			Metric:    m,
+			Method:    d.cfg.Method,
+			Direction: direction,
+			Severity:  d.severity(math.Abs(score)),
+			Value:     value,
+			Expected:  expected,
+			Score:     score,
+		})
+	}
+
+	// most severe first
+	sort.SliceStable(anomalies, func(i, j int) bool {
+		return math.Abs(anomalies[i].Score) > math.Abs(anomalies[j].Score)
+	})
+	return anomalies
+}
+
+func (d *Detector) severity(score float64) string {
+	switch {
+	case score >= 2*d.cfg.Threshold:
+		return SeverityCritical
+	case score >= 1.5*d.cfg.Threshold:
+		return SeverityMajor
+	}
+	return SeverityMinor
+}
+
+// Values extracts the metrics a detector can watch from a window
+// latency is left out of empty windows, which have none
+func Values(metrics *models.WindowMetrics) map[string]float64 {
+	values := map[string]float64{
+		MetricEvents:     float64(metrics.TotalEvents),
+		MetricTweets:     float64(metrics.TotalTweets),
+		MetricUpdates:    float64(metrics.UpdatedTweets),
+		MetricDeletes:    float64(metrics.DeletedTweets),
+		MetricEngagement: float64(metrics.TotalEngagement),
+	}
+	if metrics.TotalEvents > 0 {
+		values[MetricP99Latency] = float64(metrics.P99Latency) / float64(time.Millisecond)
+	}
+	return values
+}
+
+func knownMetric(name string) bool {
+	_, ok := Values(&models.WindowMetrics{TotalEvents: 1})[name]
+	return ok
+}
+
+// ParseMetrics splits a comma separated metric list
+func ParseMetrics(value string) []string {
+	var metrics []string
+	for _, m := range strings.Split(value, ",") {
+		if m = strings.TrimSpace(m); m != "" {
+			metrics = append(metrics, m)
+		}
+	}
+	return metrics
+}
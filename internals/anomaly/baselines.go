@@ -0,0 +1,156 @@
+package anomaly
+
+import (
+	"math"
+	"sort"
+)
+
+// ewmaBaseline tracks an exponentially weighted mean and variance
+type ewmaBaseline struct {
+	alpha    float64
+	mean     float64
+	variance float64
+	n        int
+}
+
+func newEWMABaseline(alpha float64) *ewmaBaseline {
+	return &ewmaBaseline{alpha: alpha}
+}
+
+func (b *ewmaBaseline) expect() (float64, float64) {
+	return b.mean, math.Sqrt(b.variance)
+}
+
+func (b *ewmaBaseline) update(x float64) {
+	b.n++
+	if b.n == 1 {
+		b.mean = x
+		return
+	}
+	diff := x - b.mean
+	incr := b.alpha * diff
+	b.mean += incr
+	b.variance = (1 - b.alpha) * (b.variance + diff*incr)
+}
+
+func (b *ewmaBaseline) observations() int { return b.n }
+
+// madBaseline keeps the last size values and compares against their median
+// the median absolute deviation is scaled to match a standard deviation
+type madBaseline struct {
+	values []float64
+	next   int
+	n      int
+}
+
+func newMADBaseline(size int) *madBaseline {
+	return &madBaseline{values: make([]float64, 0, size)}
+}
+
+func (b *madBaseline) expect() (float64, float64) {
+	if len(b.values) == 0 {
+		return 0, 0
+	}
+	med := median(append([]float64(nil), b.values...))
+
+	dev := make([]float64, len(b.values))
+	for i, v := range b.values {
+		dev[i] = math.Abs(v - med)
+	}
+	return med, 1.4826 * median(dev)
+}
+
+func (b *madBaseline) update(x float64) {
+	b.n++
+	if len(b.values) < cap(b.values) {
+		b.values = append(b.values, x)
+		return
+	}
+	b.values[b.next] = x
+	b.next = (b.next + 1) % len(b.values)
+}
+
+func (b *madBaseline) observations() int { return b.n }
+
+func median(values []float64) float64 {
+	sort.Float64s(values)
+	mid := len(values) / 2
+	if len(values)%2 == 0 {
+		return (values[mid-1] + values[mid]) / 2
+	}
+	return values[mid]
+}
+
+// holtWintersBaseline is an additive triple exponential smoothing forecast
+// its deviation is an EWMA of the squared forecast errors
+type holtWintersBaseline struct {
+	alpha, beta, gamma float64
+	season             []float64
+	level, trend       float64
+	errVariance        float64
+	n                  int
+	first              []float64 // the first season, used to initialise
+}
+
+func newHoltWintersBaseline(alpha, beta, gamma float64, seasonLength int) *holtWintersBaseline {
+	return &holtWintersBaseline{
+		alpha:  alpha,
+		beta:   beta,
+		gamma:  gamma,
+		season: make([]float64, seasonLength),
+	}
+}
+
+func (b *holtWintersBaseline) expect() (float64, float64) {
+	if b.n < len(b.season) {
+		return mean(b.first), 0
+	}
+	return b.forecast(), math.Sqrt(b.errVariance)
+}
+
+func (b *holtWintersBaseline) forecast() float64 {
+	return b.level + b.trend + b.season[b.n%len(b.season)]
+}
+
+func (b *holtWintersBaseline) update(x float64) {
+	m := len(b.season)
+	if b.n < m {
+		// collect one season before smoothing
+		b.first = append(b.first, x)
+		b.n++
+		if b.n == m {
+			b.level = mean(b.first)
+			for i, v := range b.first {
+				b.season[i] = v - b.level
+			}
+		}
+		return
+	}
+
+	i := b.n % m
+	residual := x - b.forecast()
+	if b.n == m {
+		b.errVariance = residual * residual
+	} else {
+		b.errVariance = (1-b.alpha)*b.errVariance + b.alpha*residual*residual
+	}
+
+	prevLevel := b.level
+	b.level = b.alpha*(x-b.season[i]) + (1-b.alpha)*(b.level+b.trend)
+	b.trend = b.beta*(b.level-prevLevel) + (1-b.beta)*b.trend
+	b.season[i] = b.gamma*(x-b.level) + (1-b.gamma)*b.season[i]
+	b.n++
+}
+
+func (b *holtWintersBaseline) observations() int { return b.n }
+
+func mean(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	return sum / float64(len(values))
+}
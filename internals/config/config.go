@@ -0,0 +1,349 @@
+// Package config is the typed configuration of the whole pipeline
+// values come from defaults, then an optional YAML file, then env, then flags
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/Udehlee/tweet-stream/internals/anomaly"
+	"github.com/Udehlee/tweet-stream/internals/data/generator"
+	"github.com/Udehlee/tweet-stream/internals/data/simulated"
+	"github.com/Udehlee/tweet-stream/internals/storage"
+	"github.com/Udehlee/tweet-stream/internals/trending"
+	"gopkg.in/yaml.v3"
+)
+
+type Config struct {
+	Log        LogConfig        `yaml:"log"`
+	Reload     ReloadConfig     `yaml:"reload"`
+	Shutdown   ShutdownConfig   `yaml:"shutdown"`
+	Simulation SimulationConfig `yaml:"simulation"`
+	Pipeline   PipelineConfig   `yaml:"pipeline"`
+	GRPC       GRPCConfig       `yaml:"grpc"`
+	Ingest     IngestConfig     `yaml:"ingest"`
+	HTTP       HTTPConfig       `yaml:"http"`
+	Hub        HubConfig        `yaml:"hub"`
+	Aggregator AggregatorConfig `yaml:"aggregator"`
+	Anomaly    AnomalyConfig    `yaml:"anomaly"`
+	Trending   TrendingConfig   `yaml:"trending"`
+	Metrics    MetricsConfig    `yaml:"metrics"`
+	Influx     InfluxConfig     `yaml:"influx"`
+}
+
+type LogConfig struct {
+	Level string `yaml:"level"` // zerolog level name
+}
+
+type ReloadConfig struct {
+	PollInterval time.Duration `yaml:"poll_interval"` // how often the config file is checked for changes, 0 only reloads on SIGHUP
+}
+
+type ShutdownConfig struct {
+	Timeout time.Duration `yaml:"timeout"` // deadline for stopping every component before exiting with an error
+}
+
+type SimulationConfig struct {
+	Seed         int64              `yaml:"seed"`     // 0 draws a random seed
+	Scenario     string             `yaml:"scenario"` // YAML or JSON scenario file, replaces the fixed interval
+	Interval     time.Duration      `yaml:"interval"`
+	Mix          map[string]float64 `yaml:"mix"` // operation weights when no scenario is set, empty is an even mix
+	UserPoolSize int                `yaml:"user_pool_size"`
+	Engagement   EngagementConfig   `yaml:"engagement"`
+}
+
+// EngagementConfig shapes which tweets get likes, retweets and comments
+type EngagementConfig struct {
+	PopularityExponent float64       `yaml:"popularity_exponent"` // the n-th most engaged tweet weighs 1/n^exponent, 0 ignores popularity
+	AgeHalfLife        time.Duration `yaml:"age_half_life"`       // a tweet weighs half as much every time it ages this much, 0 ignores age
+	Candidates         int           `yaml:"candidates"`          // most engaged and newest tweets weighed per pick, each
+}
+
+// PipelineConfig sizes the channels between components
+type PipelineConfig struct {
+	GeneratedBuffer int `yaml:"generated_buffer"` // emitter to hub
+	StreamBuffer    int `yaml:"stream_buffer"`    // processor to aggregator
+}
+
+type GRPCConfig struct {
+	Addr            string `yaml:"addr"`
+	ProcessorTarget string `yaml:"processor_target"`
+}
+
+// IngestConfig tunes the IngestTweets RPC
+type IngestConfig struct {
+	IdempotencyKeys int     `yaml:"idempotency_keys"` // keys remembered for safe retries
+	HighWatermark   float64 `yaml:"high_watermark"`   // pipeline fill, 0 to 1, at which ingest streams pause
+}
+
+// HTTPConfig sets up the JSON, Server-Sent Events and WebSocket gateway
+type HTTPConfig struct {
+	Addr      string        `yaml:"addr"`       // empty disables the gateway
+	KeepAlive time.Duration `yaml:"keep_alive"` // comment interval on idle event streams
+	Heartbeat time.Duration `yaml:"heartbeat"`  // WebSocket ping interval, two missed pongs drop the client
+}
+
+type HubConfig struct {
+	BufferSize         int    `yaml:"buffer_size"`
+	SlowConsumerPolicy string `yaml:"slow_consumer_policy"`
+	RetentionSize      int    `yaml:"retention_size"`
+	RetentionPath      string `yaml:"retention_path"` // empty keeps the retention log in memory
+}
+
+type AggregatorConfig struct {
+	Window          time.Duration `yaml:"window"`           // tumbling window used when windows is empty
+	Windows         []string      `yaml:"windows"`          // e.g. tumbling:5s, sliding:5m/5s, session:30s
+	WatermarkDelay  time.Duration `yaml:"watermark_delay"`  // out of order tolerance
+	AllowedLateness time.Duration `yaml:"allowed_lateness"` // 0 means twice the window
+	IdleTimeout     time.Duration `yaml:"idle_timeout"`     // quiet time before the watermark follows the wall clock, 0 disables
+	TopHashtags     int           `yaml:"top_hashtags"`
+}
+
+type AnomalyConfig struct {
+	Method       string   `yaml:"method"`
+	Metrics      []string `yaml:"metrics"`
+	Threshold    float64  `yaml:"threshold"`
+	Warmup       int      `yaml:"warmup"`
+	Alpha        float64  `yaml:"alpha"`
+	SeasonLength int      `yaml:"season_length"`
+	WindowSize   int      `yaml:"window_size"`
+}
+
+type TrendingConfig struct {
+	Capacity      int           `yaml:"capacity"`
+	ShortHalfLife time.Duration `yaml:"short_half_life"`
+	LongHalfLife  time.Duration `yaml:"long_half_life"`
+	MinCount      float64       `yaml:"min_count"`
+	Top           int           `yaml:"top"`
+}
+
+type MetricsConfig struct {
+	Sinks    []string `yaml:"sinks"`
+	FilePath string   `yaml:"file_path"`
+}
+
+type InfluxConfig struct {
+	URL    string `yaml:"url"`
+	Token  string `yaml:"token"`
+	Org    string `yaml:"org"`
+	Bucket string `yaml:"bucket"`
+
+	BatchSize     int           `yaml:"batch_size"`
+	FlushInterval time.Duration `yaml:"flush_interval"`
+	QueueSize     int           `yaml:"queue_size"`
+	MaxRetries    int           `yaml:"max_retries"`
+	SpoolPath     string        `yaml:"spool_path"`
+
+	HashtagsPerWindow int `yaml:"hashtags_per_window"`
+	UsersPerWindow    int `yaml:"users_per_window"`
+	MaxHashtagSeries  int `yaml:"max_hashtag_series"`
+	MaxUserSeries     int `yaml:"max_user_series"`
+}
+
+// Default returns the settings the service ran with before it was configurable
+func Default() *Config {
+	buffer := storage.DefaultBufferConfig()
+	limits := storage.DefaultSeriesLimits()
+	detector := anomaly.DefaultConfig()
+	trends := trending.DefaultConfig()
+	engagement := generator.DefaultEngagementConfig()
+
+	return &Config{
+		Log: LogConfig{
+			Level: "info",
+		},
+		Reload: ReloadConfig{
+			PollInterval: 2 * time.Second,
+		},
+		Shutdown: ShutdownConfig{
+			Timeout: 15 * time.Second,
+		},
+		Simulation: SimulationConfig{
+			Interval:     time.Second,
+			UserPoolSize: simulated.DefaultUserPoolSize,
+			Engagement: EngagementConfig{
+				PopularityExponent: engagement.PopularityExponent,
+				AgeHalfLife:        engagement.AgeHalfLife,
+				Candidates:         engagement.Candidates,
+			},
+		},
+		Pipeline: PipelineConfig{
+			GeneratedBuffer: 50,
+			StreamBuffer:    50,
+		},
+		GRPC: GRPCConfig{
+			Addr:            ":50051",
+			ProcessorTarget: "localhost:50051",
+		},
+		Ingest: IngestConfig{
+			IdempotencyKeys: 100000,
+			HighWatermark:   0.8,
+		},
+		HTTP: HTTPConfig{
+			Addr:      ":8080",
+			KeepAlive: 15 * time.Second,
+			Heartbeat: 30 * time.Second,
+		},
+		Hub: HubConfig{
+			BufferSize:         50,
+			SlowConsumerPolicy: "drop_oldest",
+			RetentionSize:      1000,
+		},
+		Aggregator: AggregatorConfig{
+			Window:         5 * time.Second,
+			WatermarkDelay: 2 * time.Second,
+			IdleTimeout:    5 * time.Second,
+			TopHashtags:    5,
+		},
+		Anomaly: AnomalyConfig{
+			Method:       detector.Method,
+			Metrics:      detector.Metrics,
+			Threshold:    detector.Threshold,
+			Warmup:       detector.Warmup,
+			Alpha:        detector.Alpha,
+			SeasonLength: detector.SeasonLength,
+			WindowSize:   detector.WindowSize,
+		},
+		Trending: TrendingConfig{
+			Capacity:      trends.Capacity,
+			ShortHalfLife: trends.ShortHalfLife,
+			LongHalfLife:  trends.LongHalfLife,
+			MinCount:      trends.MinCount,
+			Top:           10,
+		},
+		Metrics: MetricsConfig{
+			Sinks:    []string{storage.SinkInflux},
+			FilePath: "metrics.ndjson",
+		},
+		Influx: InfluxConfig{
+			BatchSize:         buffer.BatchSize,
+			FlushInterval:     buffer.FlushInterval,
+			QueueSize:         buffer.QueueSize,
+			MaxRetries:        buffer.MaxRetries,
+			HashtagsPerWindow: limits.HashtagsPerWindow,
+			UsersPerWindow:    limits.UsersPerWindow,
+			MaxHashtagSeries:  limits.MaxHashtagSeries,
+			MaxUserSeries:     limits.MaxUserSeries,
+		},
+	}
+}
+
+// Load builds the configuration from defaults, the file at path when set,
+// the environment and finally any flags set on the command line
+func Load(path string, flags *Flags) (*Config, error) {
+	cfg := Default()
+
+	if path != "" {
+		if err := cfg.loadFile(path); err != nil {
+			return nil, err
+		}
+	}
+
+	envErr := cfg.applyEnv(os.LookupEnv)
+
+	if flags != nil {
+		flags.apply(cfg)
+	}
+	cfg.normalize()
+
+	if err := errors.Join(envErr, cfg.Validate()); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// normalize lowercases names matched case insensitively
+func (c *Config) normalize() {
+	for i, sink := range c.Metrics.Sinks {
+		c.Metrics.Sinks[i] = strings.ToLower(strings.TrimSpace(sink))
+	}
+	c.Hub.SlowConsumerPolicy = strings.ToLower(strings.TrimSpace(c.Hub.SlowConsumerPolicy))
+	c.Log.Level = strings.ToLower(strings.TrimSpace(c.Log.Level))
+}
+
+// loadFile decodes a YAML file over the current values, unknown keys are errors
+func (c *Config) loadFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read config: %w", err)
+	}
+
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(c); err != nil {
+		return fmt.Errorf("failed to decode config %s: %w", path, err)
+	}
+	return nil
+}
+
+// AllowedLatenessOrDefault resolves the zero default to twice the window
+func (a AggregatorConfig) AllowedLatenessOrDefault() time.Duration {
+	if a.AllowedLateness > 0 {
+		return a.AllowedLateness
+	}
+	return 2 * a.Window
+}
+
+// DetectorConfig converts the section for the anomaly package
+func (a AnomalyConfig) DetectorConfig() anomaly.Config {
+	cfg := anomaly.DefaultConfig()
+	cfg.Method = a.Method
+	cfg.Metrics = a.Metrics
+	cfg.Threshold = a.Threshold
+	cfg.Warmup = a.Warmup
+	cfg.Alpha = a.Alpha
+	cfg.SeasonLength = a.SeasonLength
+	cfg.WindowSize = a.WindowSize
+	return cfg
+}
+
+// GeneratorConfig converts the section for the generator package
+func (e EngagementConfig) GeneratorConfig() generator.EngagementConfig {
+	return generator.EngagementConfig{
+		PopularityExponent: e.PopularityExponent,
+		AgeHalfLife:        e.AgeHalfLife,
+		Candidates:         e.Candidates,
+	}
+}
+
+// TrackerConfig converts the section for the trending package
+func (t TrendingConfig) TrackerConfig() trending.Config {
+	return trending.Config{
+		Capacity:      t.Capacity,
+		ShortHalfLife: t.ShortHalfLife,
+		LongHalfLife:  t.LongHalfLife,
+		MinCount:      t.MinCount,
+	}
+}
+
+// SinkConfig converts the metrics and influx sections for the storage package
+func (c *Config) SinkConfig() *storage.SinkConfig {
+	buffer := storage.DefaultBufferConfig()
+	buffer.BatchSize = c.Influx.BatchSize
+	buffer.FlushInterval = c.Influx.FlushInterval
+	buffer.QueueSize = c.Influx.QueueSize
+	buffer.MaxRetries = c.Influx.MaxRetries
+	buffer.SpoolPath = c.Influx.SpoolPath
+
+	return &storage.SinkConfig{
+		Sinks:    c.Metrics.Sinks,
+		FilePath: c.Metrics.FilePath,
+		Influx: storage.InfluxConfig{
+			URL:    c.Influx.URL,
+			Token:  c.Influx.Token,
+			Org:    c.Influx.Org,
+			Bucket: c.Influx.Bucket,
+		},
+		Buffer: buffer,
+		Limits: storage.SeriesLimits{
+			HashtagsPerWindow: c.Influx.HashtagsPerWindow,
+			UsersPerWindow:    c.Influx.UsersPerWindow,
+			MaxHashtagSeries:  c.Influx.MaxHashtagSeries,
+			MaxUserSeries:     c.Influx.MaxUserSeries,
+		},
+	}
+}
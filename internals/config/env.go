@@ -0,0 +1,165 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// lookupFunc matches os.LookupEnv
+type lookupFunc func(string) (string, bool)
+
+// env applies environment overrides, remembering values that do not parse
+type env struct {
+	lookup lookupFunc
+	errs   []error
+}
+
+// applyEnv overrides settings from the environment variables the service
+// has always read, plus one for every other hard coded value
+func (c *Config) applyEnv(lookup lookupFunc) error {
+	e := &env{lookup: lookup}
+
+	e.string("LOG_LEVEL", &c.Log.Level)
+	e.duration("CONFIG_POLL_INTERVAL", &c.Reload.PollInterval)
+	e.duration("SHUTDOWN_TIMEOUT", &c.Shutdown.Timeout)
+
+	e.int64("SIMULATION_SEED", &c.Simulation.Seed)
+	e.string("SIMULATION_SCENARIO", &c.Simulation.Scenario)
+	e.duration("GENERATOR_INTERVAL", &c.Simulation.Interval)
+	e.int("SIMULATION_USER_POOL_SIZE", &c.Simulation.UserPoolSize)
+	e.float("SIMULATION_ENGAGEMENT_POPULARITY_EXPONENT", &c.Simulation.Engagement.PopularityExponent)
+	e.duration("SIMULATION_ENGAGEMENT_AGE_HALF_LIFE", &c.Simulation.Engagement.AgeHalfLife)
+	e.int("SIMULATION_ENGAGEMENT_CANDIDATES", &c.Simulation.Engagement.Candidates)
+
+	e.int("PIPELINE_GENERATED_BUFFER", &c.Pipeline.GeneratedBuffer)
+	e.int("PIPELINE_STREAM_BUFFER", &c.Pipeline.StreamBuffer)
+
+	e.string("GRPC_ADDR", &c.GRPC.Addr)
+	e.string("GRPC_PROCESSOR_TARGET", &c.GRPC.ProcessorTarget)
+
+	e.int("INGEST_IDEMPOTENCY_KEYS", &c.Ingest.IdempotencyKeys)
+	e.float("INGEST_HIGH_WATERMARK", &c.Ingest.HighWatermark)
+
+	e.string("HTTP_ADDR", &c.HTTP.Addr)
+	e.duration("HTTP_KEEP_ALIVE", &c.HTTP.KeepAlive)
+	e.duration("HTTP_HEARTBEAT", &c.HTTP.Heartbeat)
+
+	e.int("HUB_BUFFER_SIZE", &c.Hub.BufferSize)
+	e.string("HUB_SLOW_CONSUMER_POLICY", &c.Hub.SlowConsumerPolicy)
+	e.int("HUB_RETENTION_SIZE", &c.Hub.RetentionSize)
+	e.string("HUB_RETENTION_PATH", &c.Hub.RetentionPath)
+
+	e.duration("AGGREGATOR_WINDOW", &c.Aggregator.Window)
+	e.list("AGGREGATOR_WINDOWS", &c.Aggregator.Windows)
+	e.duration("AGGREGATOR_WATERMARK_DELAY", &c.Aggregator.WatermarkDelay)
+	e.duration("AGGREGATOR_ALLOWED_LATENESS", &c.Aggregator.AllowedLateness)
+	e.duration("AGGREGATOR_IDLE_TIMEOUT", &c.Aggregator.IdleTimeout)
+	e.int("AGGREGATOR_TOP_HASHTAGS", &c.Aggregator.TopHashtags)
+
+	e.string("ANOMALY_METHOD", &c.Anomaly.Method)
+	e.list("ANOMALY_METRICS", &c.Anomaly.Metrics)
+	e.float("ANOMALY_THRESHOLD", &c.Anomaly.Threshold)
+	e.int("ANOMALY_WARMUP", &c.Anomaly.Warmup)
+	e.int("ANOMALY_SEASON_LENGTH", &c.Anomaly.SeasonLength)
+
+	e.int("TRENDING_CAPACITY", &c.Trending.Capacity)
+	e.duration("TRENDING_SHORT_HALF_LIFE", &c.Trending.ShortHalfLife)
+	e.duration("TRENDING_LONG_HALF_LIFE", &c.Trending.LongHalfLife)
+	e.int("TRENDING_TOP", &c.Trending.Top)
+
+	e.list("METRICS_SINKS", &c.Metrics.Sinks)
+	e.string("METRICS_FILE_PATH", &c.Metrics.FilePath)
+
+	e.string("INFLUXDB_URL", &c.Influx.URL)
+	e.string("INFLUXDB_TOKEN", &c.Influx.Token)
+	e.string("INFLUXDB_ORG", &c.Influx.Org)
+	e.string("INFLUXDB_BUCKET", &c.Influx.Bucket)
+	e.int("INFLUXDB_BATCH_SIZE", &c.Influx.BatchSize)
+	e.duration("INFLUXDB_FLUSH_INTERVAL", &c.Influx.FlushInterval)
+	e.int("INFLUXDB_QUEUE_SIZE", &c.Influx.QueueSize)
+	e.int("INFLUXDB_MAX_RETRIES", &c.Influx.MaxRetries)
+	e.string("INFLUXDB_SPOOL_PATH", &c.Influx.SpoolPath)
+	e.int("INFLUXDB_HASHTAGS_PER_WINDOW", &c.Influx.HashtagsPerWindow)
+	e.int("INFLUXDB_USERS_PER_WINDOW", &c.Influx.UsersPerWindow)
+	e.int("INFLUXDB_MAX_HASHTAG_SERIES", &c.Influx.MaxHashtagSeries)
+	e.int("INFLUXDB_MAX_USER_SERIES", &c.Influx.MaxUserSeries)
+
+	return errors.Join(e.errs...)
+}
+
+// value returns a non empty variable
+func (e *env) value(name string) (string, bool) {
+	v, ok := e.lookup(name)
+	v = strings.TrimSpace(v)
+	return v, ok && v != ""
+}
+
+func (e *env) fail(name string, err error) {
+	e.errs = append(e.errs, &FieldError{Field: name, Err: err})
+}
+
+func (e *env) string(name string, dst *string) {
+	if v, ok := e.value(name); ok {
+		*dst = v
+	}
+}
+
+func (e *env) int(name string, dst *int) {
+	if v, ok := e.value(name); ok {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			e.fail(name, fmt.Errorf("%q is not an integer", v))
+			return
+		}
+		*dst = n
+	}
+}
+
+func (e *env) int64(name string, dst *int64) {
+	if v, ok := e.value(name); ok {
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			e.fail(name, fmt.Errorf("%q is not an integer", v))
+			return
+		}
+		*dst = n
+	}
+}
+
+func (e *env) float(name string, dst *float64) {
+	if v, ok := e.value(name); ok {
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			e.fail(name, fmt.Errorf("%q is not a number", v))
+			return
+		}
+		*dst = f
+	}
+}
+
+func (e *env) duration(name string, dst *time.Duration) {
+	if v, ok := e.value(name); ok {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			e.fail(name, fmt.Errorf("%q is not a duration like 5s", v))
+			return
+		}
+		*dst = d
+	}
+}
+
+// list splits a comma separated variable
+func (e *env) list(name string, dst *[]string) {
+	if v, ok := e.value(name); ok {
+		var items []string
+		for _, item := range strings.Split(v, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		*dst = items
+	}
+}
@@ -0,0 +1,63 @@
+package config
+
+import (
+	"flag"
+	"strings"
+	"time"
+)
+
+// Flags are the command line overrides, only flags that were set apply
+type Flags struct {
+	fs *flag.FlagSet
+
+	Path     string
+	seed     int64
+	scenario string
+	grpcAddr string
+	httpAddr string
+	window   time.Duration
+	interval time.Duration
+	sinks    string
+}
+
+// RegisterFlags defines the config flags on fs
+func RegisterFlags(fs *flag.FlagSet) *Flags {
+	f := &Flags{fs: fs}
+	fs.StringVar(&f.Path, "config", "", "YAML config file, env and flags override it")
+	fs.Int64Var(&f.seed, "seed", 0, "seed for a reproducible simulation, 0 draws a random one")
+	fs.StringVar(&f.scenario, "scenario", "", "YAML or JSON scenario file driving the generator instead of a fixed interval")
+	fs.StringVar(&f.grpcAddr, "grpc-addr", "", "address the gRPC server listens on")
+	fs.StringVar(&f.httpAddr, "http-addr", "", "address the HTTP gateway listens on, empty disables it")
+	fs.DurationVar(&f.window, "window", 0, "tumbling aggregation window")
+	fs.DurationVar(&f.interval, "interval", 0, "generator interval when no scenario is set")
+	fs.StringVar(&f.sinks, "metrics-sinks", "", "comma separated metrics sinks: influx, file, memory")
+	return f
+}
+
+// apply copies the flags that were set on the command line into cfg
+func (f *Flags) apply(cfg *Config) {
+	f.fs.Visit(func(fl *flag.Flag) {
+		switch fl.Name {
+		case "seed":
+			cfg.Simulation.Seed = f.seed
+		case "scenario":
+			cfg.Simulation.Scenario = f.scenario
+		case "grpc-addr":
+			cfg.GRPC.Addr = f.grpcAddr
+		case "http-addr":
+			cfg.HTTP.Addr = f.httpAddr
+		case "window":
+			cfg.Aggregator.Window = f.window
+		case "interval":
+			cfg.Simulation.Interval = f.interval
+		case "metrics-sinks":
+			var sinks []string
+			for _, s := range strings.Split(f.sinks, ",") {
+				if s = strings.TrimSpace(s); s != "" {
+					sinks = append(sinks, s)
+				}
+			}
+			cfg.Metrics.Sinks = sinks
+		}
+	})
+}
@@ -0,0 +1,172 @@
+package config
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"reflect"
+	"slices"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// safeFields can change while the service runs, anything else needs a restart
+var safeFields = []string{
+	"log.level",
+	"simulation.interval",
+	"simulation.mix",
+	"anomaly.threshold",
+	"aggregator.top_hashtags",
+	"trending.top",
+}
+
+// scenarioFields are ignored while a scenario drives the generator,
+// the runner takes its rates and operations from the scenario file
+var scenarioFields = []string{
+	"simulation.interval",
+	"simulation.mix",
+}
+
+// Watcher reloads the configuration on SIGHUP or when the file changes
+// and hands the safe changes to apply
+type Watcher struct {
+	path    string
+	flags   *Flags
+	current *Config
+	logger  *zerolog.Logger
+	apply   func(*Config)
+
+	modTime time.Time
+	size    int64
+}
+
+func NewWatcher(path string, flags *Flags, current *Config, logger *zerolog.Logger, apply func(*Config)) *Watcher {
+	w := &Watcher{
+		path:    path,
+		flags:   flags,
+		current: current,
+		logger:  logger,
+		apply:   apply,
+	}
+	w.modTime, w.size = w.stat()
+	return w
+}
+
+// Run watches until ctx is done
+func (w *Watcher) Run(ctx context.Context) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	var poll <-chan time.Time
+	if interval := w.current.Reload.PollInterval; w.path != "" && interval > 0 {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		poll = ticker.C
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case <-hup:
+			w.logger.Info().Msg("SIGHUP received, reloading config")
+			w.Reload()
+
+		case <-poll:
+			if modTime, size := w.stat(); !modTime.Equal(w.modTime) || size != w.size {
+				w.logger.Info().Msgf("config file %s changed, reloading", w.path)
+				w.Reload()
+			}
+		}
+	}
+}
+
+// Reload loads the configuration again and applies the safe changes
+// an invalid configuration is rejected as a whole and the running one is kept
+func (w *Watcher) Reload() {
+	w.modTime, w.size = w.stat()
+
+	next, err := Load(w.path, w.flags)
+	if err != nil {
+		w.logger.Error().Err(err).Msg("config reload rejected, keeping the running configuration")
+		return
+	}
+
+	scenario := w.current.Simulation.Scenario
+	var safe, unsafe, driven []string
+	for _, field := range Changes(w.current, next) {
+		switch {
+		case scenario != "" && slices.Contains(scenarioFields, field):
+			driven = append(driven, field)
+		case slices.Contains(safeFields, field):
+			safe = append(safe, field)
+		default:
+			unsafe = append(unsafe, field)
+		}
+	}
+
+	if len(unsafe) > 0 {
+		w.logger.Warn().Msgf("config reload ignored changes to %s: they need a restart", strings.Join(unsafe, ", "))
+	}
+	if len(driven) > 0 {
+		w.logger.Warn().Msgf("config reload ignored changes to %s: scenario %s drives the generator", strings.Join(driven, ", "), scenario)
+	}
+	if len(safe) == 0 {
+		w.logger.Info().Msg("config reloaded, no live setting changed")
+		return
+	}
+
+	applied := *w.current
+	applied.Log.Level = next.Log.Level
+	if scenario == "" {
+		applied.Simulation.Interval = next.Simulation.Interval
+		applied.Simulation.Mix = next.Simulation.Mix
+	}
+	applied.Anomaly.Threshold = next.Anomaly.Threshold
+	applied.Aggregator.TopHashtags = next.Aggregator.TopHashtags
+	applied.Trending.Top = next.Trending.Top
+
+	w.current = &applied
+	w.apply(&applied)
+	w.logger.Info().Msgf("config reloaded, applied %s", strings.Join(safe, ", "))
+}
+
+func (w *Watcher) stat() (time.Time, int64) {
+	if w.path == "" {
+		return time.Time{}, 0
+	}
+	info, err := os.Stat(w.path)
+	if err != nil {
+		return time.Time{}, 0
+	}
+	return info.ModTime(), info.Size()
+}
+
+// Changes lists the YAML paths of the settings that differ between a and b
+func Changes(a, b *Config) []string {
+	var changed []string
+	diff(reflect.ValueOf(*a), reflect.ValueOf(*b), "", &changed)
+	return changed
+}
+
+func diff(a, b reflect.Value, path string, changed *[]string) {
+	if a.Kind() != reflect.Struct {
+		if !reflect.DeepEqual(a.Interface(), b.Interface()) {
+			*changed = append(*changed, path)
+		}
+		return
+	}
+
+	for i := 0; i < a.NumField(); i++ {
+		name, _, _ := strings.Cut(a.Type().Field(i).Tag.Get("yaml"), ",")
+		if path != "" {
+			name = path + "." + name
+		}
+		diff(a.Field(i), b.Field(i), name, changed)
+	}
+}
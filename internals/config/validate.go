@@ -0,0 +1,144 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/Udehlee/tweet-stream/internals/aggregator"
+	"github.com/Udehlee/tweet-stream/internals/data/generator"
+	"github.com/Udehlee/tweet-stream/internals/hub"
+	"github.com/Udehlee/tweet-stream/internals/storage"
+	"github.com/rs/zerolog"
+)
+
+// FieldError is a single invalid setting
+// Field is the YAML path of the setting, e.g. aggregator.window
+type FieldError struct {
+	Field string
+	Err   error
+}
+
+func (e *FieldError) Error() string {
+	return e.Field + ": " + e.Err.Error()
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// validator collects every FieldError instead of stopping at the first
+type validator struct {
+	errs []error
+}
+
+func (v *validator) check(ok bool, field, format string, args ...any) {
+	if !ok {
+		v.add(field, fmt.Errorf(format, args...))
+	}
+}
+
+func (v *validator) add(field string, err error) {
+	if err != nil {
+		v.errs = append(v.errs, &FieldError{Field: field, Err: err})
+	}
+}
+
+// Validate checks every section and joins all problems into one error
+func (c *Config) Validate() error {
+	v := &validator{}
+
+	if _, err := zerolog.ParseLevel(c.Log.Level); err != nil {
+		v.add("log.level", err)
+	}
+	v.check(c.Reload.PollInterval >= 0, "reload.poll_interval", "cannot be negative")
+	v.check(c.Shutdown.Timeout > 0, "shutdown.timeout", "must be positive")
+
+	v.check(c.Simulation.Interval > 0, "simulation.interval", "must be positive")
+	v.add("simulation.mix", validateMix(c.Simulation.Mix))
+	v.check(c.Simulation.UserPoolSize > 0, "simulation.user_pool_size", "must be positive")
+	v.check(c.Simulation.Engagement.PopularityExponent >= 0, "simulation.engagement.popularity_exponent", "cannot be negative")
+	v.check(c.Simulation.Engagement.AgeHalfLife >= 0, "simulation.engagement.age_half_life", "cannot be negative")
+	v.check(c.Simulation.Engagement.Candidates > 0, "simulation.engagement.candidates", "must be positive")
+
+	v.check(c.Pipeline.GeneratedBuffer > 0, "pipeline.generated_buffer", "must be positive")
+	v.check(c.Pipeline.StreamBuffer > 0, "pipeline.stream_buffer", "must be positive")
+
+	v.check(c.GRPC.Addr != "", "grpc.addr", "is required")
+	v.check(c.GRPC.ProcessorTarget != "", "grpc.processor_target", "is required")
+
+	v.check(c.Ingest.IdempotencyKeys > 0, "ingest.idempotency_keys", "must be positive")
+	v.check(c.Ingest.HighWatermark > 0 && c.Ingest.HighWatermark <= 1, "ingest.high_watermark", "must be above 0 and at most 1")
+
+	v.check(c.HTTP.KeepAlive > 0, "http.keep_alive", "must be positive")
+	v.check(c.HTTP.Heartbeat > 0, "http.heartbeat", "must be positive")
+
+	v.check(c.Hub.BufferSize > 0, "hub.buffer_size", "must be positive")
+	v.check(c.Hub.RetentionSize > 0, "hub.retention_size", "must be positive")
+	if _, err := hub.ParsePolicy(c.Hub.SlowConsumerPolicy); err != nil {
+		v.add("hub.slow_consumer_policy", err)
+	}
+
+	v.check(c.Aggregator.Window > 0, "aggregator.window", "must be positive")
+	v.check(c.Aggregator.WatermarkDelay >= 0, "aggregator.watermark_delay", "cannot be negative")
+	v.check(c.Aggregator.AllowedLateness >= 0, "aggregator.allowed_lateness", "cannot be negative")
+	v.check(c.Aggregator.IdleTimeout >= 0, "aggregator.idle_timeout", "cannot be negative")
+	v.check(c.Aggregator.TopHashtags > 0, "aggregator.top_hashtags", "must be positive")
+	if len(c.Aggregator.Windows) > 0 {
+		_, err := c.Aggregator.WindowSpecs()
+		v.add("aggregator.windows", err)
+	}
+
+	v.add("anomaly", c.Anomaly.DetectorConfig().Validate())
+
+	v.check(c.Trending.Capacity > 0, "trending.capacity", "must be positive")
+	v.check(c.Trending.ShortHalfLife > 0, "trending.short_half_life", "must be positive")
+	v.check(c.Trending.LongHalfLife > c.Trending.ShortHalfLife, "trending.long_half_life", "must be longer than short_half_life")
+	v.check(c.Trending.Top > 0, "trending.top", "must be positive")
+
+	v.check(len(c.Metrics.Sinks) > 0, "metrics.sinks", "at least one sink is required")
+	for _, sink := range c.Metrics.Sinks {
+		known := []string{storage.SinkInflux, storage.SinkFile, storage.SinkMemory}
+		v.check(slices.Contains(known, sink), "metrics.sinks", "unknown sink %q, expected one of %s", sink, strings.Join(known, ", "))
+	}
+	if slices.Contains(c.Metrics.Sinks, storage.SinkFile) {
+		v.check(c.Metrics.FilePath != "", "metrics.file_path", "is required by the file sink")
+	}
+	if slices.Contains(c.Metrics.Sinks, storage.SinkInflux) {
+		sink := c.SinkConfig()
+		v.add("influx", sink.Influx.Validate())
+		v.check(c.Influx.BatchSize > 0, "influx.batch_size", "must be positive")
+		v.check(c.Influx.FlushInterval > 0, "influx.flush_interval", "must be positive")
+		v.check(c.Influx.QueueSize > 0, "influx.queue_size", "must be positive")
+		v.check(c.Influx.MaxRetries > 0, "influx.max_retries", "must be positive")
+	}
+
+	return errors.Join(v.errs...)
+}
+
+// validateMix checks operation weights the way the generator will
+func validateMix(mix map[string]float64) error {
+	var total float64
+	for op, w := range mix {
+		if !slices.Contains(generator.OperationNames, op) {
+			return fmt.Errorf("unknown operation %q", op)
+		}
+		if w < 0 {
+			return fmt.Errorf("weight of %q cannot be negative", op)
+		}
+		total += w
+	}
+	if len(mix) > 0 && total == 0 {
+		return errors.New("weights add up to zero")
+	}
+	return nil
+}
+
+// WindowSpecs parses the window definitions, or the tumbling window when there are none
+func (a AggregatorConfig) WindowSpecs() ([]aggregator.WindowSpec, error) {
+	if len(a.Windows) == 0 {
+		return []aggregator.WindowSpec{aggregator.TumblingWindow(a.Window)}, nil
+	}
+	return aggregator.ParseWindowSpecs(strings.Join(a.Windows, ","))
+}
@@ -0,0 +1,41 @@
+package client
+
+import (
+	"context"
+
+	"github.com/Udehlee/tweet-stream/utils"
+)
+
+var offlineQuotes = []string{
+	"The best time to plant a tree was twenty years ago. The second best time is now.",
+	"Simplicity is prerequisite for reliability.",
+	"Make it work, make it right, make it fast.",
+	"Premature optimization is the root of all evil.",
+	"Talk is cheap. Show me the code.",
+	"Programs must be written for people to read, and only incidentally for machines to execute.",
+	"Clear is better than clever.",
+	"Don't communicate by sharing memory, share memory by communicating.",
+	"A little copying is better than a little dependency.",
+	"Errors are values.",
+	"Whatever you do, do it well. Do it so well that when people see you do it, they will want to come back.",
+	"Believe you can and you're halfway there.",
+	"Nothing will work unless you do.",
+	"It always seems impossible until it's done.",
+	"Small deeds done are better than great deeds planned.",
+	"Quality is not an act, it is a habit.",
+}
+
+// OfflineClient returns quote-like tweets from a built in list
+// so seeded runs never depend on the network
+type OfflineClient struct {
+	rand *utils.Random
+}
+
+func NewOfflineClient(r *utils.Random) *OfflineClient {
+	return &OfflineClient{rand: r}
+}
+
+// RandomTweet returns a quote from the built in list
+func (oc *OfflineClient) RandomTweet(ctx context.Context) (string, error) {
+	return offlineQuotes[oc.rand.Intn(len(offlineQuotes))], nil
+}
@@ -0,0 +1,143 @@
+package generator
+
+import (
+	"context"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/Udehlee/tweet-stream/models"
+)
+
+// EngagementConfig shapes which tweets receive simulated engagement
+// PopularityExponent gives a power-law over the engagement rank of a tweet,
+// the n-th most engaged tweet is picked with weight 1/n^PopularityExponent
+// AgeHalfLife halves the weight of a tweet every time its age grows by that much
+// Candidates bounds a pick to that many of the most engaged and of the newest tweets,
+// older tweets that never took off weigh next to nothing after a few half-lives
+type EngagementConfig struct {
+	PopularityExponent float64
+	AgeHalfLife        time.Duration
+	Candidates         int
+}
+
+func DefaultEngagementConfig() EngagementConfig {
+	return EngagementConfig{
+		PopularityExponent: 1.2,
+		AgeHalfLife:        2 * time.Minute,
+		Candidates:         256,
+	}
+}
+
+// LikeRandomTweet adds a like to a live tweet
+func (gs *GeneratorService) LikeRandomTweet(ctx context.Context) {
+	gs.reactToRandomTweet(ctx, models.ReactionLike)
+}
+
+// RetweetRandomTweet adds a retweet to a live tweet
+func (gs *GeneratorService) RetweetRandomTweet(ctx context.Context) {
+	gs.reactToRandomTweet(ctx, models.ReactionRetweet)
+}
+
+// CommentRandomTweet adds a comment to a live tweet
+func (gs *GeneratorService) CommentRandomTweet(ctx context.Context) {
+	tweetID := gs.pickEngagementTarget()
+	if tweetID == "" {
+		return
+	}
+
+	content, err := gs.client.RandomTweet(ctx)
+	if err != nil {
+		content = "this one sweet me"
+	}
+
+	user, err := gs.tweetSvc.PickUser()
+	if err != nil {
+		gs.logger.Info().Msg("failed to pick a commenter")
+		return
+	}
+
+	event, err := gs.emitter.ApplyWait(ctx, func() (models.EventType, *models.Tweet, *models.Tweet, error) {
+		previous, updated, err := gs.tweetSvc.AddComment(tweetID, user, content)
+		return models.EventUpdated, updated, previous, err
+	})
+	if err != nil {
+		gs.logger.Info().Err(err).Msg("Failed to comment on tweet")
+		return
+	}
+
+	gs.publishEvent(event)
+}
+
+// reactToRandomTweet adds a reaction of the given type to a live tweet
+func (gs *GeneratorService) reactToRandomTweet(ctx context.Context, reactionType string) {
+	tweetID := gs.pickEngagementTarget()
+	if tweetID == "" {
+		return
+	}
+
+	user, err := gs.tweetSvc.PickUser()
+	if err != nil {
+		gs.logger.Info().Msg("failed to pick a reacting user")
+		return
+	}
+
+	event, err := gs.emitter.ApplyWait(ctx, func() (models.EventType, *models.Tweet, *models.Tweet, error) {
+		previous, updated, err := gs.tweetSvc.AddReaction(tweetID, user, reactionType)
+		return models.EventUpdated, updated, previous, err
+	})
+	if err != nil {
+		gs.logger.Info().Err(err).Msgf("Failed to add %s", reactionType)
+		return
+	}
+
+	gs.publishEvent(event)
+}
+
+// pickEngagementTarget picks the id of a live tweet, favouring tweets that are
+// already popular and tweets that are still fresh, empty when there is none
+func (gs *GeneratorService) pickEngagementTarget() string {
+	cfg := gs.Engagement
+	if cfg.Candidates <= 0 {
+		cfg.Candidates = DefaultEngagementConfig().Candidates
+	}
+	tweets := gs.tweetSvc.EngagementCandidates(cfg.Candidates)
+	if len(tweets) == 0 {
+		return ""
+	}
+
+	sort.Slice(tweets, func(i, j int) bool {
+		ei, ej := tweets[i].Engagement, tweets[j].Engagement
+		if ei != ej {
+			return ei > ej
+		}
+		return tweets[i].ID < tweets[j].ID
+	})
+
+	now := gs.src.Clock.Now()
+	weights := make([]float64, len(tweets))
+	var total float64
+
+	for rank, tweet := range tweets {
+		w := 1 / math.Pow(float64(rank+1), cfg.PopularityExponent)
+		if cfg.AgeHalfLife > 0 {
+			age := now.Sub(tweet.CreatedAt)
+			w *= math.Exp2(-float64(age) / float64(cfg.AgeHalfLife))
+		}
+		weights[rank] = w
+		total += w
+	}
+
+	if total == 0 {
+		return tweets[0].ID
+	}
+
+	target := gs.src.Rand.Float64() * total
+	for i, w := range weights {
+		target -= w
+		if target < 0 {
+			return tweets[i].ID
+		}
+	}
+	return tweets[len(tweets)-1].ID
+}
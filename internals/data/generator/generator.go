@@ -2,59 +2,121 @@ package generator
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"math/rand"
+	"strings"
 	"sync"
 	"time"
 
-	"github.com/Udehlee/tweet-stream/internals/data/client"
 	"github.com/Udehlee/tweet-stream/internals/data/simulated"
+	"github.com/Udehlee/tweet-stream/internals/events"
 	"github.com/Udehlee/tweet-stream/models"
+	"github.com/Udehlee/tweet-stream/utils"
 	"github.com/rs/zerolog"
 )
 
+// TweetSource supplies the text of generated tweets
+// client.Client fetches quotes over http, client.OfflineClient picks from a local list
+type TweetSource interface {
+	RandomTweet(ctx context.Context) (string, error)
+}
+
 type GeneratorService struct {
-	tweetSvc *simulated.TweetService
-	client   *client.Client
-	logger   *zerolog.Logger
-	mu       sync.Mutex
-	Publish  chan<- *models.Tweet
-	done     chan struct{}
+	tweetSvc   *simulated.TweetService
+	client     TweetSource
+	logger     *zerolog.Logger
+	mu         sync.Mutex
+	emitter    *events.Emitter
+	src        *utils.Source
+	done       chan struct{}
+	stopOnce   sync.Once
+	loops      sync.WaitGroup // running GenerateTweets loops
+	Engagement EngagementConfig
+
+	settingsMu sync.Mutex
+	mix        []weightedOp       // empty picks operations uniformly
+	intervals  chan time.Duration // interval changes for a running GenerateTweets
+}
+
+// weightedOp is an operation with the running sum of weights up to it
+type weightedOp struct {
+	name       string
+	cumulative float64
 }
 
-func NewGeneratorService(tweetSvc *simulated.TweetService, client *client.Client, logger *zerolog.Logger, publish chan<- *models.Tweet) *GeneratorService {
+func NewGeneratorService(tweetSvc *simulated.TweetService, client TweetSource, logger *zerolog.Logger, emitter *events.Emitter, src *utils.Source) *GeneratorService {
 	return &GeneratorService{
-		tweetSvc: tweetSvc,
-		client:   client,
-		logger:   logger,
-		Publish:  publish,
-		done:     make(chan struct{}),
+		tweetSvc:   tweetSvc,
+		client:     client,
+		logger:     logger,
+		emitter:    emitter,
+		src:        src,
+		done:       make(chan struct{}),
+		Engagement: DefaultEngagementConfig(),
+		intervals:  make(chan time.Duration, 1),
 	}
 }
 
+// Operation names understood by RunOperation
+const (
+	OpPost    = "post"
+	OpUpdate  = "update"
+	OpDelete  = "delete"
+	OpLike    = "like"
+	OpRetweet = "retweet"
+	OpComment = "comment"
+)
+
+// OperationNames lists every operation in a fixed order
+var OperationNames = []string{OpPost, OpUpdate, OpDelete, OpLike, OpRetweet, OpComment}
+
+var ErrStopped = errors.New("generator stopped")
+
 // PostTweet posts random tweets
 func (gs *GeneratorService) PostTweet(ctx context.Context) {
+	gs.postTweet(ctx, nil)
+}
+
+// PostWithHashtags posts a random tweet that also carries the given hashtags
+func (gs *GeneratorService) PostWithHashtags(ctx context.Context, tags ...string) {
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+
+	if gs.stopped() {
+		return
+	}
+	gs.postTweet(ctx, tags)
+}
+
+// postTweet posts a random tweet with its generated hashtags and any extra ones
+func (gs *GeneratorService) postTweet(ctx context.Context, extraTags []string) {
 	msg, err := gs.client.RandomTweet(ctx)
 	if err != nil {
 		msg = "use this tweet take flex"
 	}
 
-	tweet, err := gs.tweetSvc.CreateTweet(msg)
+	hashTags := gs.tweetSvc.GenerateHashTags(msg)
+	for _, tag := range extraTags {
+		hashTags = append(hashTags, "#"+strings.TrimPrefix(tag, "#"))
+	}
+	var tweet *models.Tweet
+	event, err := gs.emitter.ApplyWait(ctx, func() (models.EventType, *models.Tweet, *models.Tweet, error) {
+		created, err := gs.tweetSvc.CreateTweet(fmt.Sprintf("%s\n%s", msg, strings.Join(hashTags, " ")))
+		tweet = created
+		return models.EventCreated, created, nil, err
+	})
 	if err != nil {
-		gs.logger.Info().Msg("failed to post tweet")
+		gs.logger.Info().Err(err).Msg("failed to post tweet")
 		return
 	}
 
-	hashTag := gs.tweetSvc.GenerateHashTags(msg)
-	tweet.Message = fmt.Sprintf("%s\n %s", tweet.Message, hashTag)
-
-	gs.publishTweet(tweet)
+	gs.publishEvent(event)
 	gs.logger.Info().Msgf("New tweet posted %s", tweet.Message)
 }
 
 // UpdateRandomTweet updates a random existing tweet
 func (gs *GeneratorService) UpdateRandomTweet(ctx context.Context) {
-	tweet := gs.tweetSvc.GetTweet()
+	tweet := gs.tweetSvc.RandomTweet()
 	if tweet == nil {
 		return
 	}
@@ -64,65 +126,186 @@ func (gs *GeneratorService) UpdateRandomTweet(ctx context.Context) {
 		msg = "use this update hold body"
 	}
 
-	err = gs.tweetSvc.UpdateTweet(tweet.ID, msg)
+	event, err := gs.emitter.ApplyWait(ctx, func() (models.EventType, *models.Tweet, *models.Tweet, error) {
+		previous, updated, err := gs.tweetSvc.UpdateTweet(tweet.ID, msg)
+		return models.EventUpdated, updated, previous, err
+	})
 	if err != nil {
-		gs.logger.Info().Msg("Failed to update tweet")
+		gs.logger.Info().Err(err).Msg("Failed to update tweet")
 		return
 	}
 
-	tweet.Message = msg
-	gs.publishTweet(tweet)
+	gs.publishEvent(event)
 	gs.logger.Info().Msgf("Tweet updated %s", msg)
 }
 
 // DeleteRandomTweet deletes a random existing tweet
 func (gs *GeneratorService) DeleteRandomTweet(ctx context.Context) {
-	tweet := gs.tweetSvc.GetTweet()
+	tweet := gs.tweetSvc.RandomTweet()
 	if tweet == nil {
 		return
 	}
 
-	err := gs.tweetSvc.DeleteTweet(tweet.ID)
+	event, err := gs.emitter.ApplyWait(ctx, func() (models.EventType, *models.Tweet, *models.Tweet, error) {
+		deleted, err := gs.tweetSvc.DeleteTweet(tweet.ID)
+		return models.EventDeleted, deleted, nil, err
+	})
 	if err != nil {
-		gs.logger.Info().Msg("Failed to delete tweet")
+		gs.logger.Info().Err(err).Msg("Failed to delete tweet")
 		return
 	}
 
-	gs.publishTweet(tweet)
+	gs.publishEvent(event)
 	gs.logger.Info().Msgf("Tweet deleted %s", tweet.ID)
 }
 
-// PublishTweet publishes tweet to gRPC
-func (gs *GeneratorService) publishTweet(tweet *models.Tweet) {
-	if gs.Publish == nil {
+// publishEvent logs an event the emitter published
+func (gs *GeneratorService) publishEvent(event *models.TweetEvent) {
+	gs.logger.Debug().Msgf("published %s event %d for tweet %s", event.Type, event.Sequence, event.Tweet.ID)
+}
+
+// RunOperation runs the named operation
+func (gs *GeneratorService) RunOperation(ctx context.Context, name string) error {
+	op, ok := gs.operations()[name]
+	if !ok {
+		return fmt.Errorf("unknown generator operation %q", name)
+	}
+
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+
+	if gs.stopped() {
+		return ErrStopped
+	}
+	op(ctx)
+	return nil
+}
+
+// Stop ends GenerateTweets, rejects further operations
+// and waits for the operation in progress so no event is emitted after it returns
+func (gs *GeneratorService) Stop(ctx context.Context) error {
+	gs.stopOnce.Do(func() { close(gs.done) })
+
+	stopped := make(chan struct{})
+	go func() {
+		gs.loops.Wait()
+		gs.mu.Lock() // held by an operation in progress
+		gs.mu.Unlock()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// stopped reports whether Stop was called
+func (gs *GeneratorService) stopped() bool {
+	select {
+	case <-gs.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// operations maps operation names to their implementation
+func (gs *GeneratorService) operations() map[string]func(context.Context) {
+	return map[string]func(context.Context){
+		OpPost:    gs.PostTweet,
+		OpUpdate:  gs.UpdateRandomTweet,
+		OpDelete:  gs.DeleteRandomTweet,
+		OpLike:    gs.LikeRandomTweet,
+		OpRetweet: gs.RetweetRandomTweet,
+		OpComment: gs.CommentRandomTweet,
+	}
+}
+
+// SetInterval changes the interval of a running GenerateTweets
+func (gs *GeneratorService) SetInterval(interval time.Duration) {
+	if interval <= 0 {
 		return
 	}
+	// keep only the latest change
 	select {
-	case gs.Publish <- tweet:
+	case <-gs.intervals:
 	default:
-		gs.logger.Info().Msg("publish channel full, dropped tweet")
 	}
+	gs.intervals <- interval
+}
+
+// SetMix weighs the operations GenerateTweets picks
+// an empty mix picks every operation equally often
+func (gs *GeneratorService) SetMix(weights map[string]float64) error {
+	var mix []weightedOp
+	var total float64
+	for _, name := range OperationNames { // fixed order keeps seeded runs reproducible
+		w, ok := weights[name]
+		if !ok {
+			continue
+		}
+		if w < 0 {
+			return fmt.Errorf("weight of %q cannot be negative", name)
+		}
+		total += w
+		mix = append(mix, weightedOp{name: name, cumulative: total})
+	}
+	for name := range weights {
+		if _, ok := gs.operations()[name]; !ok {
+			return fmt.Errorf("unknown generator operation %q", name)
+		}
+	}
+	if len(weights) > 0 && total == 0 {
+		return fmt.Errorf("operation weights add up to zero")
+	}
+
+	gs.settingsMu.Lock()
+	gs.mix = mix
+	gs.settingsMu.Unlock()
+	return nil
+}
+
+// pickOperation draws the next operation from the mix
+func (gs *GeneratorService) pickOperation() string {
+	gs.settingsMu.Lock()
+	mix := gs.mix
+	gs.settingsMu.Unlock()
+
+	if len(mix) == 0 {
+		return OperationNames[gs.src.Rand.Intn(len(OperationNames))]
+	}
+
+	target := gs.src.Rand.Float64() * mix[len(mix)-1].cumulative
+	for _, op := range mix {
+		if target < op.cumulative {
+			return op.name
+		}
+	}
+	return mix[len(mix)-1].name
 }
 
 // GenerateTweets generates random fake tweet operations at intervals
 func (gs *GeneratorService) GenerateTweets(ctx context.Context, interval time.Duration) {
 	ticker := time.NewTicker(interval)
 
-	ops := []func(context.Context){
-		gs.PostTweet,
-		gs.UpdateRandomTweet,
-		gs.DeleteRandomTweet,
-	}
-
+	gs.loops.Add(1)
 	go func() {
+		defer gs.loops.Done()
 		defer ticker.Stop()
 		for {
 			select {
 			case <-ticker.C:
-				gs.mu.Lock()
-				op := ops[rand.Intn(len(ops))]
-				op(ctx)
-				gs.mu.Unlock()
+				name := gs.pickOperation()
+				if err := gs.RunOperation(ctx, name); err != nil {
+					gs.logger.Info().Err(err).Msg("generator operation failed")
+				}
+
+			case interval := <-gs.intervals:
+				ticker.Reset(interval)
+				gs.logger.Info().Msgf("generator interval set to %s", interval)
 
 			case <-gs.done:
 				return
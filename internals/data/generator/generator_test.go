@@ -0,0 +1,76 @@
+package generator
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/Udehlee/tweet-stream/internals/data/client"
+	"github.com/Udehlee/tweet-stream/internals/data/simulated"
+	"github.com/Udehlee/tweet-stream/internals/events"
+	"github.com/Udehlee/tweet-stream/models"
+	"github.com/Udehlee/tweet-stream/utils"
+	"github.com/rs/zerolog"
+)
+
+// generate runs ops operations picked from the default mix and returns
+// the emitted events as JSON lines, every clock is a step clock
+func generate(t *testing.T, seed int64, ops int) []byte {
+	t.Helper()
+
+	src := utils.NewSeededSource(seed)
+	src.Events = utils.NewStepClock(utils.SeedEpoch, time.Millisecond)
+	logger := zerolog.Nop()
+
+	publish := make(chan *models.TweetEvent, ops)
+	emitter, err := events.NewEmitter(publish, src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tweets := simulated.NewTweetService(&logger, simulated.NewUserRegistry(50, src), src)
+	gs := NewGeneratorService(tweets, client.NewOfflineClient(src.Rand), &logger, emitter, src)
+
+	for i := 0; i < ops; i++ {
+		if err := gs.RunOperation(context.Background(), gs.pickOperation()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	close(publish)
+
+	var out bytes.Buffer
+	enc := json.NewEncoder(&out)
+	count := 0
+	for event := range publish {
+		if err := enc.Encode(event); err != nil {
+			t.Fatal(err)
+		}
+		count++
+	}
+	if count < ops/2 {
+		t.Fatalf("seed %d emitted %d events for %d operations", seed, count, ops)
+	}
+	return out.Bytes()
+}
+
+func TestSameSeedYieldsIdenticalEvents(t *testing.T) {
+	first := generate(t, 42, 500)
+	second := generate(t, 42, 500)
+
+	if !bytes.Equal(first, second) {
+		a, b := bytes.Split(first, []byte("\n")), bytes.Split(second, []byte("\n"))
+		for i := range min(len(a), len(b)) {
+			if !bytes.Equal(a[i], b[i]) {
+				t.Fatalf("event %d differs:\n%s\n%s", i, a[i], b[i])
+			}
+		}
+		t.Fatalf("runs emitted %d and %d events", len(a), len(b))
+	}
+}
+
+func TestDifferentSeedsYieldDifferentEvents(t *testing.T) {
+	if bytes.Equal(generate(t, 42, 200), generate(t, 43, 200)) {
+		t.Fatal("seeds 42 and 43 emitted the same events")
+	}
+}
@@ -0,0 +1,254 @@
+package simulated
+
+import (
+	"cmp"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/Udehlee/tweet-stream/internals/entities"
+	"github.com/Udehlee/tweet-stream/models"
+)
+
+// TweetQuery selects live tweets, zero fields match every tweet
+type TweetQuery struct {
+	UserID  string
+	Hashtag string
+	From    time.Time // earliest CreatedAt, inclusive
+	To      time.Time // latest CreatedAt, exclusive
+}
+
+// match checks the fields the index lists did not already narrow down
+func (q TweetQuery) match(tweet *models.Tweet) bool {
+	if q.UserID != "" && (tweet.User == nil || tweet.User.UserID != q.UserID) {
+		return false
+	}
+	if q.Hashtag != "" && !slices.Contains(tweetHashtags(tweet), entities.NormalizeHashtag(q.Hashtag)) {
+		return false
+	}
+	if !q.From.IsZero() && tweet.CreatedAt.Before(q.From) {
+		return false
+	}
+	if !q.To.IsZero() && !tweet.CreatedAt.Before(q.To) {
+		return false
+	}
+	return true
+}
+
+// tweetIndex lists tweet ids in creation order overall, per author and per hashtag
+// every tweet gets a sequence number when it is added, lists hold sorted sequence
+// numbers so a cursor stays valid while tweets come and go
+// tweets are stamped as they are added, so sequence order is also CreatedAt order
+// and a time range is a slice of any list, unless the clock went backwards
+type tweetIndex struct {
+	next      uint64
+	seqs      map[string]uint64    // tweet id to sequence
+	ids       map[uint64]string    // sequence to tweet id
+	created   map[uint64]time.Time // sequence to CreatedAt
+	ordered   bool                 // CreatedAt never decreased, time ranges can be searched
+	latest    time.Time
+	all       []uint64
+	byUser    map[string][]uint64
+	byHashtag map[string][]uint64
+	popular   []popularSeq // most engaged first, at most popularSize
+}
+
+// popularSize bounds the engagement ranking kept next to the creation order
+const popularSize = 256
+
+type popularSeq struct {
+	seq        uint64
+	engagement int
+}
+
+// comparePopular orders by engagement, the older tweet first on a tie
+func comparePopular(a, b popularSeq) int {
+	if a.engagement != b.engagement {
+		return b.engagement - a.engagement
+	}
+	return cmp.Compare(a.seq, b.seq)
+}
+
+func newTweetIndex() *tweetIndex {
+	return &tweetIndex{
+		seqs:      make(map[string]uint64),
+		ids:       make(map[uint64]string),
+		created:   make(map[uint64]time.Time),
+		ordered:   true,
+		byUser:    make(map[string][]uint64),
+		byHashtag: make(map[string][]uint64),
+	}
+}
+
+// add indexes a new tweet after every tweet already indexed
+func (x *tweetIndex) add(tweet *models.Tweet) {
+	x.next++
+	seq := x.next
+	x.seqs[tweet.ID] = seq
+	x.ids[seq] = tweet.ID
+	x.created[seq] = tweet.CreatedAt
+	if tweet.CreatedAt.Before(x.latest) {
+		x.ordered = false
+	}
+	x.latest = tweet.CreatedAt
+
+	x.all = append(x.all, seq)
+	if tweet.User != nil {
+		x.byUser[tweet.User.UserID] = append(x.byUser[tweet.User.UserID], seq)
+	}
+	for _, tag := range tweetHashtags(tweet) {
+		x.byHashtag[tag] = append(x.byHashtag[tag], seq)
+	}
+}
+
+// remove drops a tweet from every list
+func (x *tweetIndex) remove(tweet *models.Tweet) {
+	seq, ok := x.seqs[tweet.ID]
+	if !ok {
+		return
+	}
+	delete(x.seqs, tweet.ID)
+	delete(x.ids, seq)
+	delete(x.created, seq)
+
+	x.all = removeSeq(x.all, seq)
+	x.popular = slices.DeleteFunc(x.popular, func(p popularSeq) bool { return p.seq == seq })
+	if tweet.User != nil {
+		removeKey(x.byUser, tweet.User.UserID, seq)
+	}
+	for _, tag := range tweetHashtags(tweet) {
+		removeKey(x.byHashtag, tag, seq)
+	}
+}
+
+// retag moves an edited tweet between hashtag lists
+func (x *tweetIndex) retag(previous, tweet *models.Tweet) {
+	seq, ok := x.seqs[tweet.ID]
+	if !ok {
+		return
+	}
+
+	before, after := tweetHashtags(previous), tweetHashtags(tweet)
+	for _, tag := range before {
+		if !slices.Contains(after, tag) {
+			removeKey(x.byHashtag, tag, seq)
+		}
+	}
+	for _, tag := range after {
+		if !slices.Contains(before, tag) {
+			x.byHashtag[tag] = insertSeq(x.byHashtag[tag], seq)
+		}
+	}
+}
+
+// engaged moves a tweet whose engagement changed to its rank in the popular list
+// a tweet pushed off the end comes back the next time it is engaged
+func (x *tweetIndex) engaged(tweet *models.Tweet) {
+	seq, ok := x.seqs[tweet.ID]
+	if !ok {
+		return
+	}
+
+	x.popular = slices.DeleteFunc(x.popular, func(p popularSeq) bool { return p.seq == seq })
+	entry := popularSeq{seq: seq, engagement: tweet.Engagement()}
+	i, _ := slices.BinarySearchFunc(x.popular, entry, comparePopular)
+	if i >= popularSize {
+		return
+	}
+	x.popular = slices.Insert(x.popular, i, entry)
+	if len(x.popular) > popularSize {
+		x.popular = x.popular[:popularSize]
+	}
+}
+
+// hot returns up to n of the most engaged tweets followed by up to n
+// of the newest ones that are not among them
+func (x *tweetIndex) hot(n int) []uint64 {
+	popular := x.popular[:min(n, len(x.popular))]
+	seqs := make([]uint64, 0, len(popular)+n)
+	seen := make(map[uint64]struct{}, len(popular))
+	for _, p := range popular {
+		seqs = append(seqs, p.seq)
+		seen[p.seq] = struct{}{}
+	}
+
+	for i := len(x.all) - 1; i >= 0 && i >= len(x.all)-n; i-- {
+		if _, ok := seen[x.all[i]]; !ok {
+			seqs = append(seqs, x.all[i])
+		}
+	}
+	return seqs
+}
+
+// candidates returns the shortest list that holds every tweet matching q
+// cut down to its time range, exact reports that every tweet in it matches
+// a time range on a clock that went backwards cannot be cut and is left to match
+func (x *tweetIndex) candidates(q TweetQuery) (list []uint64, exact bool) {
+	list = x.all
+	if q.UserID != "" {
+		list = x.byUser[q.UserID]
+	}
+	if q.Hashtag != "" {
+		if tagged := x.byHashtag[entities.NormalizeHashtag(q.Hashtag)]; len(tagged) < len(list) || q.UserID == "" {
+			list = tagged
+		}
+	}
+	exact = q.UserID == "" || q.Hashtag == ""
+
+	if q.From.IsZero() && q.To.IsZero() {
+		return list, exact
+	}
+	if !x.ordered {
+		return list, false
+	}
+
+	// from the first tweet created at or after From to the first at or after To
+	start := 0
+	if !q.From.IsZero() {
+		start = sort.Search(len(list), func(i int) bool { return !x.created[list[i]].Before(q.From) })
+	}
+	end := len(list)
+	if !q.To.IsZero() {
+		end = sort.Search(len(list), func(i int) bool { return !x.created[list[i]].Before(q.To) })
+	}
+	return list[start:max(start, end)], exact
+}
+
+// tweetHashtags returns the distinct normalized hashtags of a tweet
+func tweetHashtags(tweet *models.Tweet) []string {
+	tags := make([]string, 0, len(tweet.HashTag))
+	for _, tag := range tweet.HashTag {
+		if tag = entities.NormalizeHashtag(tag); tag != "" && !slices.Contains(tags, tag) {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// insertSeq adds seq to a sorted list
+func insertSeq(list []uint64, seq uint64) []uint64 {
+	i, found := slices.BinarySearch(list, seq)
+	if found {
+		return list
+	}
+	return slices.Insert(list, i, seq)
+}
+
+// removeSeq drops seq from a sorted list
+func removeSeq(list []uint64, seq uint64) []uint64 {
+	i, found := slices.BinarySearch(list, seq)
+	if !found {
+		return list
+	}
+	return slices.Delete(list, i, i+1)
+}
+
+// removeKey drops seq from the list under key, forgetting lists that become empty
+func removeKey(lists map[string][]uint64, key string, seq uint64) {
+	list := removeSeq(lists[key], seq)
+	if len(list) == 0 {
+		delete(lists, key)
+		return
+	}
+	lists[key] = list
+}
@@ -0,0 +1,164 @@
+package simulated
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/Udehlee/tweet-stream/models"
+	"github.com/Udehlee/tweet-stream/utils"
+	"github.com/rs/zerolog"
+)
+
+// listClock hands out the given times in order
+type listClock struct {
+	times []time.Time
+}
+
+func (c *listClock) Now() time.Time {
+	now := c.times[0]
+	c.times = c.times[1:]
+	return now
+}
+
+func newTestService(t *testing.T) *TweetService {
+	t.Helper()
+	logger := zerolog.Nop()
+	src := utils.NewSeededSource(1)
+	return NewTweetService(&logger, NewUserRegistry(5, src), src)
+}
+
+// createTweets posts n tweets by user, every other one tagged #golang
+func createTweets(t *testing.T, ts *TweetService, user *models.User, n int) []*models.Tweet {
+	t.Helper()
+	tweets := make([]*models.Tweet, n)
+	for i := range tweets {
+		msg := "plain tweet"
+		if i%2 == 0 {
+			msg = "tagged tweet #golang"
+		}
+		tweet, err := ts.CreateTweetBy(user, msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		tweets[i] = tweet
+	}
+	return tweets
+}
+
+func ids(tweets []*models.Tweet) []string {
+	out := make([]string, len(tweets))
+	for i, tweet := range tweets {
+		out[i] = tweet.ID
+	}
+	return out
+}
+
+func pick(tweets []*models.Tweet, indexes ...int) []string {
+	out := make([]string, len(indexes))
+	for i, index := range indexes {
+		out[i] = tweets[index].ID
+	}
+	return out
+}
+
+func TestTimeRangeBoundaries(t *testing.T) {
+	ts := newTestService(t)
+	user, _ := ts.PickUser()
+	tweets := createTweets(t, ts, user, 8)
+	at := func(i int) time.Time { return tweets[i].CreatedAt }
+
+	tests := []struct {
+		name string
+		q    TweetQuery
+		want []string
+	}{
+		{name: "from is inclusive, to exclusive", q: TweetQuery{From: at(2), To: at(5)}, want: pick(tweets, 2, 3, 4)},
+		{name: "from only", q: TweetQuery{From: at(6)}, want: pick(tweets, 6, 7)},
+		{name: "to only", q: TweetQuery{To: at(2)}, want: pick(tweets, 0, 1)},
+		{name: "between two tweets", q: TweetQuery{From: at(2).Add(time.Nanosecond), To: at(4)}, want: pick(tweets, 3)},
+		{name: "empty range", q: TweetQuery{From: at(3), To: at(3)}, want: nil},
+		{name: "reversed range", q: TweetQuery{From: at(5), To: at(2)}, want: nil},
+		{name: "before every tweet", q: TweetQuery{To: at(0)}, want: nil},
+		{name: "after every tweet", q: TweetQuery{From: at(7).Add(time.Nanosecond)}, want: nil},
+		{name: "author and range", q: TweetQuery{UserID: user.UserID, From: at(1), To: at(4)}, want: pick(tweets, 1, 2, 3)},
+		{name: "hashtag and range", q: TweetQuery{Hashtag: "#GoLang", From: at(1), To: at(7)}, want: pick(tweets, 2, 4, 6)},
+		{name: "author, hashtag and range", q: TweetQuery{UserID: user.UserID, Hashtag: "golang", From: at(0), To: at(3)}, want: pick(tweets, 0, 2)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			listed, _ := ts.ListTweets(tt.q, 0, 0)
+			if got := ids(listed); !slices.Equal(got, tt.want) {
+				t.Errorf("listed %v, want %v", got, tt.want)
+			}
+			if got := ts.CountTweets(tt.q); got != len(tt.want) {
+				t.Errorf("counted %d, want %d", got, len(tt.want))
+			}
+		})
+	}
+}
+
+func TestTimeRangeAfterUpdateAndDelete(t *testing.T) {
+	ts := newTestService(t)
+	user, _ := ts.PickUser()
+	tweets := createTweets(t, ts, user, 6)
+	q := TweetQuery{From: tweets[1].CreatedAt, To: tweets[5].CreatedAt}
+
+	// an edit moves UpdatedAt and the hashtags but not the place in creation order
+	if _, _, err := ts.UpdateTweet(tweets[2].ID, "edited #rust"); err != nil {
+		t.Fatal(err)
+	}
+	listed, _ := ts.ListTweets(q, 0, 0)
+	if got, want := ids(listed), pick(tweets, 1, 2, 3, 4); !slices.Equal(got, want) {
+		t.Errorf("after update listed %v, want %v", got, want)
+	}
+	tagged, _ := ts.ListTweets(TweetQuery{Hashtag: "golang", From: q.From, To: q.To}, 0, 0)
+	if got, want := ids(tagged), pick(tweets, 4); !slices.Equal(got, want) {
+		t.Errorf("after update #golang listed %v, want %v", got, want)
+	}
+
+	if _, err := ts.DeleteTweet(tweets[3].ID); err != nil {
+		t.Fatal(err)
+	}
+	if got := ts.CountTweets(q); got != 3 {
+		t.Errorf("after delete counted %d, want 3", got)
+	}
+
+	// pages walk the range in creation order across the deleted tweet
+	var paged []string
+	var cursor uint64
+	for {
+		page, next := ts.ListTweets(q, cursor, 1)
+		paged = append(paged, ids(page)...)
+		if next == 0 {
+			break
+		}
+		cursor = next
+	}
+	if want := pick(tweets, 1, 2, 4); !slices.Equal(paged, want) {
+		t.Errorf("paged %v, want %v", paged, want)
+	}
+}
+
+func TestTimeRangeWhenClockWentBackwards(t *testing.T) {
+	ts := newTestService(t)
+	user, _ := ts.PickUser()
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	ts.src.Clock = &listClock{times: []time.Time{
+		base,
+		base.Add(2 * time.Second),
+		base.Add(time.Second), // the clock stepped back
+		base.Add(3 * time.Second),
+	}}
+	tweets := createTweets(t, ts, user, 4)
+
+	q := TweetQuery{From: base.Add(time.Second), To: base.Add(3 * time.Second)}
+	listed, _ := ts.ListTweets(q, 0, 0)
+	if got, want := ids(listed), pick(tweets, 1, 2); !slices.Equal(got, want) {
+		t.Errorf("listed %v, want %v", got, want)
+	}
+	if got := ts.CountTweets(q); got != 2 {
+		t.Errorf("counted %d, want 2", got)
+	}
+}
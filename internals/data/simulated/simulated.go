@@ -2,11 +2,13 @@ package simulated
 
 import (
 	"fmt"
-	"math/rand"
+	"slices"
 	"strings"
 	"sync"
 	"time"
+	"unicode"
 
+	"github.com/Udehlee/tweet-stream/internals/entities"
 	"github.com/Udehlee/tweet-stream/models"
 	"github.com/Udehlee/tweet-stream/utils"
 	"github.com/rs/zerolog"
@@ -14,117 +16,320 @@ import (
 
 type TweetService struct {
 	tweet  map[string]*models.Tweet
+	index  *tweetIndex
+	users  *UserRegistry
+	src    *utils.Source
 	logger *zerolog.Logger
 	mu     sync.RWMutex
 }
 
-func NewTweetService(logger *zerolog.Logger) *TweetService {
+// TweetNotFoundError reports an id that is not a live tweet
+type TweetNotFoundError struct {
+	ID string
+}
+
+func (e *TweetNotFoundError) Error() string {
+	return fmt.Sprintf("tweet with the id %s is not found", e.ID)
+}
+
+// DuplicateReactionError reports a user reacting the same way to a tweet twice
+type DuplicateReactionError struct {
+	UserID       string
+	ReactionType string
+	TweetID      string
+}
+
+func (e *DuplicateReactionError) Error() string {
+	return fmt.Sprintf("user %s already added a %s to tweet %s", e.UserID, e.ReactionType, e.TweetID)
+}
+
+func NewTweetService(logger *zerolog.Logger, users *UserRegistry, src *utils.Source) *TweetService {
 	Ts := &TweetService{
 		tweet:  make(map[string]*models.Tweet),
+		index:  newTweetIndex(),
+		users:  users,
+		src:    src,
 		logger: logger,
 	}
 
 	return Ts
 }
 
-// CreateUser create user
-// and assigns verification status
-func (ts *TweetService) CreateUser() (*models.User, error) {
-	name := utils.GenerateUser()
-	status := ts.isVerifiedOrNot()
+// Users returns the simulated user pool
+func (ts *TweetService) Users() *UserRegistry {
+	return ts.users
+}
 
-	user := &models.User{
-		UserID: utils.GenerateID(),
-		Name:   name,
-		Status: status,
+// PickUser picks a user from the pool weighted by activity level
+func (ts *TweetService) PickUser() (*models.User, error) {
+	user := ts.users.PickActive()
+	if user == nil {
+		return nil, fmt.Errorf("user pool is empty")
 	}
-
 	return user, nil
 }
 
-// CreateTweet creates a single tweet
+// CreateTweet creates a single tweet by an author picked from the pool
 func (ts *TweetService) CreateTweet(msg string) (*models.Tweet, error) {
+	return ts.CreateTweetBy(nil, msg)
+}
+
+// CreateTweetBy creates a single tweet by user, a nil user picks the author from the pool
+func (ts *TweetService) CreateTweetBy(user *models.User, msg string) (*models.Tweet, error) {
 	ts.mu.Lock()
 	defer ts.mu.Unlock()
 
-	id := utils.GenerateID()
-	user, err := ts.CreateUser()
-	if err != nil {
-		return nil, fmt.Errorf("failed to create user: %v", err)
+	id := ts.src.IDs.NewID()
+	if _, taken := ts.tweet[id]; taken {
+		return nil, fmt.Errorf("tweet id %s is already taken", id)
+	}
+
+	if user == nil {
+		var err error
+		if user, err = ts.PickUser(); err != nil {
+			return nil, fmt.Errorf("failed to pick author: %v", err)
+		}
 	}
 
 	tweet := &models.Tweet{
 		ID:        id,
 		User:      user,
 		Message:   msg,
-		CreatedAt: time.Now(),
+		CreatedAt: ts.src.Clock.Now(),
 	}
+	setEntities(tweet)
 
 	ts.tweet[id] = tweet
+	ts.index.add(tweet)
 	ts.logger.Info().Msgf("New tweet by %s || %s\n %s", user.Name, user.Status, msg)
-	return tweet, nil
+	return tweet.Clone(), nil
 
 }
 
 // UpdateTweet updates an existing tweet
-func (ts *TweetService) UpdateTweet(tweetId, msg string) error {
+// and returns copies of the tweet before and after the edit
+func (ts *TweetService) UpdateTweet(tweetId, msg string) (*models.Tweet, *models.Tweet, error) {
 	ts.mu.Lock()
 	defer ts.mu.Unlock()
 
 	tweet, found := ts.tweet[tweetId]
 	if !found {
-		return fmt.Errorf("tweet with the id %s is not found", tweetId)
+		return nil, nil, &TweetNotFoundError{ID: tweetId}
 	}
 
+	previous := tweet.Clone()
 	tweet.Message = msg
-	tweet.UpdatedAt = time.Now()
+	tweet.UpdatedAt = ts.src.Clock.Now()
+	setEntities(tweet)
+	ts.index.retag(previous, tweet)
 
 	ts.logger.Info().Msgf("tweet with the id %s has been updated successfully with the msg %s", tweetId, msg)
-	return nil
+	return previous, tweet.Clone(), nil
 }
 
 // DeleteTweet deletes a tweet
-func (ts *TweetService) DeleteTweet(tweetId string) error {
+// and returns the last version of it
+func (ts *TweetService) DeleteTweet(tweetId string) (*models.Tweet, error) {
 	ts.mu.Lock()
 	defer ts.mu.Unlock()
 
-	_, found := ts.tweet[tweetId]
+	tweet, found := ts.tweet[tweetId]
 	if !found {
-		return fmt.Errorf("tweet with the id %s is not found", tweetId)
+		return nil, &TweetNotFoundError{ID: tweetId}
 	}
 
 	delete(ts.tweet, tweetId)
+	ts.index.remove(tweet)
 	ts.logger.Info().Msgf("tweet with the id %s has been deleted successfully", tweetId)
-	return nil
+	return tweet, nil
 }
 
-// GetTweet returns any random tweets
-func (ts *TweetService) GetTweet() *models.Tweet {
+// AddReaction records a like or retweet by user on an existing tweet
+// and returns copies of the tweet before and after the reaction
+func (ts *TweetService) AddReaction(tweetId string, user *models.User, reactionType string) (*models.Tweet, *models.Tweet, error) {
+	if reactionType != models.ReactionLike && reactionType != models.ReactionRetweet {
+		return nil, nil, fmt.Errorf("unsupported reaction type %q", reactionType)
+	}
+
 	ts.mu.Lock()
 	defer ts.mu.Unlock()
 
-	if len(ts.tweet) == 0 {
-		ts.logger.Info().Msg("no tweet is seen")
-		return nil
+	tweet, found := ts.tweet[tweetId]
+	if !found {
+		return nil, nil, &TweetNotFoundError{ID: tweetId}
+	}
+
+	for _, r := range tweet.Reactions {
+		if r.User != nil && r.User.UserID == user.UserID && r.ReactionType == reactionType {
+			return nil, nil, &DuplicateReactionError{UserID: user.UserID, ReactionType: reactionType, TweetID: tweetId}
+		}
+	}
+
+	previous := tweet.Clone()
+	tweet.Reactions = append(tweet.Reactions, models.Reaction{
+		User:         user,
+		ReactionType: reactionType,
+		Count:        1,
+	})
+	tweet.UpdatedAt = ts.src.Clock.Now()
+	ts.index.engaged(tweet)
+
+	ts.logger.Info().Msgf("%s added a %s to tweet %s", user.Name, reactionType, tweetId)
+	return previous, tweet.Clone(), nil
+}
+
+// AddComment adds a comment by user to an existing tweet
+// and returns copies of the tweet before and after the comment
+func (ts *TweetService) AddComment(tweetId string, user *models.User, content string) (*models.Tweet, *models.Tweet, error) {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	tweet, found := ts.tweet[tweetId]
+	if !found {
+		return nil, nil, &TweetNotFoundError{ID: tweetId}
 	}
 
-	keys := make([]string, 0, len(ts.tweet))
-	for k := range ts.tweet {
-		keys = append(keys, k)
+	previous := tweet.Clone()
+	now := ts.src.Clock.Now()
+	tweet.Comments = append(tweet.Comments, models.Comment{
+		User:     user,
+		Content:  content,
+		PostedAt: now,
+	})
+	tweet.UpdatedAt = now
+	ts.index.engaged(tweet)
+
+	ts.logger.Info().Msgf("%s commented on tweet %s", user.Name, tweetId)
+	return previous, tweet.Clone(), nil
+}
+
+// Tweets returns copies of every live tweet
+func (ts *TweetService) Tweets() []*models.Tweet {
+	ts.mu.RLock()
+	defer ts.mu.RUnlock()
+
+	tweets := make([]*models.Tweet, 0, len(ts.tweet))
+	for _, tweet := range ts.tweet {
+		tweets = append(tweets, tweet.Clone())
+	}
+	return tweets
+}
+
+// EngagementCandidate is what the generator weighs when it picks a tweet to engage with
+type EngagementCandidate struct {
+	ID         string
+	Engagement int
+	CreatedAt  time.Time
+}
+
+// EngagementCandidates returns up to n of the most engaged live tweets
+// and up to n of the newest ones, each tweet once and without copying the store
+func (ts *TweetService) EngagementCandidates(n int) []EngagementCandidate {
+	ts.mu.RLock()
+	defer ts.mu.RUnlock()
+
+	seqs := ts.index.hot(n)
+	candidates := make([]EngagementCandidate, 0, len(seqs))
+	for _, seq := range seqs {
+		tweet := ts.tweet[ts.index.ids[seq]]
+		candidates = append(candidates, EngagementCandidate{
+			ID:         tweet.ID,
+			Engagement: tweet.Engagement(),
+			CreatedAt:  tweet.CreatedAt,
+		})
+	}
+	return candidates
+}
+
+// GetTweet returns a copy of the live tweet with the given id
+func (ts *TweetService) GetTweet(tweetId string) (*models.Tweet, bool) {
+	ts.mu.RLock()
+	defer ts.mu.RUnlock()
+
+	tweet, found := ts.tweet[tweetId]
+	if !found {
+		return nil, false
+	}
+	return tweet.Clone(), true
+}
+
+// ListTweets returns up to limit tweets matching q in creation order,
+// starting after the cursor, and the cursor of the next page or 0 when there is none
+// cursors stay valid while tweets are created and deleted
+func (ts *TweetService) ListTweets(q TweetQuery, after uint64, limit int) ([]*models.Tweet, uint64) {
+	ts.mu.RLock()
+	defer ts.mu.RUnlock()
+
+	list, _ := ts.index.candidates(q)
+	start, _ := slices.BinarySearch(list, after+1)
+
+	var tweets []*models.Tweet
+	var last uint64
+	for _, seq := range list[start:] {
+		tweet := ts.tweet[ts.index.ids[seq]]
+		if !q.match(tweet) {
+			continue
+		}
+		if limit > 0 && len(tweets) == limit {
+			return tweets, last
+		}
+		tweets = append(tweets, tweet.Clone())
+		last = seq
+	}
+	return tweets, 0
+}
+
+// CountTweets counts the live tweets matching q
+// an author or hashtag alone, with or without a time range, is answered
+// from the index without a scan
+func (ts *TweetService) CountTweets(q TweetQuery) int {
+	ts.mu.RLock()
+	defer ts.mu.RUnlock()
+
+	list, exact := ts.index.candidates(q)
+	if exact {
+		return len(list)
+	}
+
+	count := 0
+	for _, seq := range list {
+		if q.match(ts.tweet[ts.index.ids[seq]]) {
+			count++
+		}
+	}
+	return count
+}
+
+// RandomTweet returns any random tweets
+func (ts *TweetService) RandomTweet() *models.Tweet {
+	ts.mu.RLock()
+	defer ts.mu.RUnlock()
+
+	all := ts.index.all
+	if len(all) == 0 {
+		ts.logger.Info().Msg("no tweet is seen")
+		return nil
 	}
 
-	randKey := keys[rand.Intn(len(keys))]
-	return ts.tweet[randKey]
+	// the index keeps creation order, unlike the map, so seeded runs stay reproducible
+	seq := all[ts.src.Rand.Intn(len(all))]
+	return ts.tweet[ts.index.ids[seq]].Clone()
 }
 
 // GenerateHashTags generates hashtags from tweet
+// keeping only the letters and digits of long words so every tag parses back
 func (ts *TweetService) GenerateHashTags(msg string) []string {
 	var tags []string
 	words := strings.Fields(msg)
 
 	for _, w := range words {
-		trimmed := strings.Trim(w, ".,!?")
-		if len(trimmed) > 5 {
+		trimmed := strings.Map(func(r rune) rune {
+			if unicode.IsLetter(r) || unicode.IsDigit(r) {
+				return r
+			}
+			return -1
+		}, w)
+		if len([]rune(trimmed)) > 5 {
 			tags = append(tags, "#"+trimmed)
 		}
 
@@ -136,8 +341,11 @@ func (ts *TweetService) GenerateHashTags(msg string) []string {
 	return tags
 }
 
-// isVerifiedOrNot assigns verified or unverified  to user
-func (ts *TweetService) isVerifiedOrNot() string {
-	statuses := []string{"verified", "unverified"}
-	return statuses[rand.Intn(len(statuses))]
+// setEntities stores the hashtags, mentions, urls and cashtags found in the message
+func setEntities(tweet *models.Tweet) {
+	e := entities.Extract(tweet.Message)
+	tweet.HashTag = e.Hashtags
+	tweet.Mentions = e.Mentions
+	tweet.URLs = e.URLs
+	tweet.Cashtags = e.Cashtags
 }
@@ -0,0 +1,238 @@
+package simulated
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Udehlee/tweet-stream/models"
+	"github.com/Udehlee/tweet-stream/utils"
+)
+
+const (
+	DefaultUserPoolSize = 200
+
+	followsPerUser     = 5   // edges every new user adds to the follower graph
+	activityShape      = 1.5 // pareto shape, a few users tweet a lot
+	maxActivityLevel   = 50.0
+	verifiedPercentile = 0.9 // users above this follower percentile get verified
+	accountAgeRange    = 5 * 365 * 24 * time.Hour
+)
+
+// UserRegistry is the persistent pool of simulated users
+// with stable ids and a synthetic follower graph
+type UserRegistry struct {
+	mu         sync.RWMutex
+	src        *utils.Source
+	users      map[string]*models.User
+	order      []string // ids in creation order so paging is stable
+	followers  map[string][]string
+	following  map[string][]string
+	cumulative []float64 // running sum of activity levels in order
+}
+
+// NewUserRegistry generates size users and wires them into a follower graph
+// drawing every random choice from src
+func NewUserRegistry(size int, src *utils.Source) *UserRegistry {
+	if size <= 0 {
+		size = DefaultUserPoolSize
+	}
+
+	r := &UserRegistry{
+		src:       src,
+		users:     make(map[string]*models.User, size),
+		order:     make([]string, 0, size),
+		followers: make(map[string][]string, size),
+		following: make(map[string][]string, size),
+	}
+
+	handles := make(map[string]struct{}, size)
+	now := src.Clock.Now()
+	for len(r.order) < size {
+		name := utils.GenerateUser(src.Rand)
+		handle := fmt.Sprintf("%s_%d", strings.ToLower(strings.ReplaceAll(name, "-", "_")), src.Rand.Intn(1000))
+		id := src.IDs.NewID()
+		if _, taken := handles[handle]; taken {
+			continue
+		}
+		if _, taken := r.users[id]; taken {
+			continue
+		}
+		handles[handle] = struct{}{}
+
+		user := &models.User{
+			UserID:        id,
+			Name:          name,
+			Handle:        handle,
+			DisplayName:   name,
+			CreatedAt:     now.Add(-time.Duration(src.Rand.Int63n(int64(accountAgeRange)))).Truncate(time.Second),
+			ActivityLevel: paretoActivity(src.Rand),
+		}
+		r.users[user.UserID] = user
+		r.order = append(r.order, user.UserID)
+	}
+
+	r.buildFollowerGraph()
+	r.assignVerification()
+
+	var sum float64
+	r.cumulative = make([]float64, len(r.order))
+	for i, id := range r.order {
+		sum += r.users[id].ActivityLevel
+		r.cumulative[i] = sum
+	}
+
+	return r
+}
+
+// Get returns the user with the given id
+func (r *UserRegistry) Get(userID string) (*models.User, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	user, ok := r.users[userID]
+	return user, ok
+}
+
+// List returns up to limit users starting at offset, in creation order
+// and the offset of the next page or 0 when there is none
+func (r *UserRegistry) List(offset, limit int) ([]*models.User, int) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.page(r.order, offset, limit)
+}
+
+// Followers returns a page of the users following userID
+func (r *UserRegistry) Followers(userID string, offset, limit int) ([]*models.User, int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if _, ok := r.users[userID]; !ok {
+		return nil, 0, fmt.Errorf("user with the id %s is not found", userID)
+	}
+	users, next := r.page(r.followers[userID], offset, limit)
+	return users, next, nil
+}
+
+// Following returns a page of the users userID follows
+func (r *UserRegistry) Following(userID string, offset, limit int) ([]*models.User, int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if _, ok := r.users[userID]; !ok {
+		return nil, 0, fmt.Errorf("user with the id %s is not found", userID)
+	}
+	users, next := r.page(r.following[userID], offset, limit)
+	return users, next, nil
+}
+
+// Len returns the number of users in the pool
+func (r *UserRegistry) Len() int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return len(r.order)
+}
+
+// PickActive returns a user chosen with probability proportional to activity level
+func (r *UserRegistry) PickActive() *models.User {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if len(r.order) == 0 {
+		return nil
+	}
+
+	target := r.src.Rand.Float64() * r.cumulative[len(r.cumulative)-1]
+	i := sort.SearchFloat64s(r.cumulative, target)
+	if i >= len(r.order) {
+		i = len(r.order) - 1
+	}
+	return r.users[r.order[i]]
+}
+
+// page slices ids and resolves them to users, callers must hold r.mu
+func (r *UserRegistry) page(ids []string, offset, limit int) ([]*models.User, int) {
+	if offset < 0 || offset >= len(ids) {
+		return nil, 0
+	}
+
+	end := len(ids)
+	if limit > 0 && offset+limit < end {
+		end = offset + limit
+	}
+
+	users := make([]*models.User, 0, end-offset)
+	for _, id := range ids[offset:end] {
+		users = append(users, r.users[id])
+	}
+
+	if end == len(ids) {
+		return users, 0
+	}
+	return users, end
+}
+
+// buildFollowerGraph wires users with preferential attachment
+// so a handful of accounts end up with most of the followers
+func (r *UserRegistry) buildFollowerGraph() {
+	// every user appears once plus once per follower, picking uniformly
+	// from this list picks a user proportionally to followers+1
+	targets := make([]string, 0, len(r.order)*(followsPerUser+1))
+
+	for i, id := range r.order {
+		seen := make(map[string]struct{}, followsPerUser)
+		for tries := 0; len(seen) < followsPerUser && len(seen) < i && tries < followsPerUser*10; tries++ {
+			target := targets[r.src.Rand.Intn(len(targets))]
+			if _, dup := seen[target]; dup {
+				continue
+			}
+			seen[target] = struct{}{}
+			r.follow(id, target)
+			targets = append(targets, target)
+		}
+		targets = append(targets, id)
+	}
+
+	for _, id := range r.order {
+		user := r.users[id]
+		user.FollowerCount = len(r.followers[id])
+		user.FollowingCount = len(r.following[id])
+	}
+}
+
+// follow records that follower follows followee
+func (r *UserRegistry) follow(follower, followee string) {
+	r.followers[followee] = append(r.followers[followee], follower)
+	r.following[follower] = append(r.following[follower], followee)
+}
+
+// assignVerification verifies the most followed accounts
+// and a small random share of everyone else
+func (r *UserRegistry) assignVerification() {
+	counts := make([]int, 0, len(r.order))
+	for _, id := range r.order {
+		counts = append(counts, r.users[id].FollowerCount)
+	}
+	sort.Ints(counts)
+	threshold := counts[int(float64(len(counts)-1)*verifiedPercentile)]
+
+	for _, id := range r.order {
+		user := r.users[id]
+		if user.FollowerCount > threshold || r.src.Rand.Float64() < 0.05 {
+			user.Status = "verified"
+		} else {
+			user.Status = "unverified"
+		}
+	}
+}
+
+// paretoActivity draws an activity level from a pareto distribution
+func paretoActivity(r *utils.Random) float64 {
+	u := 1 - r.Float64() // (0, 1]
+	return math.Min(math.Pow(u, -1/activityShape), maxActivityLevel)
+}
@@ -0,0 +1,214 @@
+package entities
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+const (
+	maxMentionLength = 15
+	maxCashtagLength = 6
+)
+
+// urlPattern finds explicit links and bare www. links
+var urlPattern = regexp.MustCompile(`(?i)\b(?:https?://|www\.)[^\s<>"]+`)
+
+// Entities holds the structured entities found in tweet text
+// hashtags and mentions are lowercase without their sigil,
+// cashtags are uppercase without the $
+type Entities struct {
+	Hashtags []string
+	Mentions []string
+	URLs     []string
+	Cashtags []string
+}
+
+// Extract parses hashtags, mentions, urls and cashtags from text
+// every list keeps the order of first appearance without duplicates
+func Extract(text string) Entities {
+	text = norm.NFC.String(text)
+
+	var e Entities
+	urls := newSet()
+	for _, loc := range urlPattern.FindAllStringIndex(text, -1) {
+		raw := trimTrailingPunctuation(text[loc[0]:loc[1]])
+		if u := normalizeURL(raw); u != "" {
+			urls.add(&e.URLs, u)
+		}
+		// blank the link out so anchors and query strings are not read as entities
+		text = text[:loc[0]] + strings.Repeat(" ", loc[1]-loc[0]) + text[loc[1]:]
+	}
+
+	hashtags, mentions, cashtags := newSet(), newSet(), newSet()
+	var prev rune
+	for i, r := range text {
+		if i > 0 && (isWordRune(prev) || prev == '&') {
+			prev = r
+			continue
+		}
+		prev = r
+
+		rest := text[i+utf8.RuneLen(r):]
+		switch r {
+		case '#', '＃':
+			if tag := scanHashtag(rest); tag != "" {
+				hashtags.add(&e.Hashtags, strings.ToLower(tag))
+			}
+		case '@', '＠':
+			if mention := scanMention(rest); mention != "" {
+				mentions.add(&e.Mentions, strings.ToLower(mention))
+			}
+		case '$':
+			if symbol := scanCashtag(rest); symbol != "" {
+				cashtags.add(&e.Cashtags, strings.ToUpper(symbol))
+			}
+		}
+	}
+
+	return e
+}
+
+// NormalizeHashtag lowercases a tag and strips a leading #
+func NormalizeHashtag(tag string) string {
+	tag = strings.TrimSpace(norm.NFC.String(tag))
+	tag = strings.TrimPrefix(strings.TrimPrefix(tag, "#"), "＃")
+	return strings.ToLower(tag)
+}
+
+// scanHashtag reads letters, marks, digits and underscores
+// a tag made only of digits is not a hashtag
+func scanHashtag(s string) string {
+	end, hasLetter := 0, false
+	for i, r := range s {
+		if !isWordRune(r) {
+			break
+		}
+		if !unicode.IsDigit(r) && r != '_' {
+			hasLetter = true
+		}
+		end = i + utf8.RuneLen(r)
+	}
+	if !hasLetter || (end < len(s) && isSigil(rune(s[end]))) {
+		return ""
+	}
+	return s[:end]
+}
+
+// scanMention reads an ascii handle of at most 15 characters
+func scanMention(s string) string {
+	end := 0
+	for end < len(s) && isHandleByte(s[end]) {
+		end++
+	}
+	if end == 0 || end > maxMentionLength {
+		return ""
+	}
+	if end < len(s) && (s[end] == '@' || isWordRune(rune(s[end]))) {
+		return ""
+	}
+	return s[:end]
+}
+
+// scanCashtag reads a ticker symbol like $TSLA or $BRK.B
+func scanCashtag(s string) string {
+	end := 0
+	for end < len(s) && isASCIILetter(s[end]) {
+		end++
+	}
+	if end == 0 || end > maxCashtagLength {
+		return ""
+	}
+
+	if end+1 < len(s) && (s[end] == '.' || s[end] == '_') && isASCIILetter(s[end+1]) {
+		suffix := end + 1
+		for suffix < len(s) && isASCIILetter(s[suffix]) {
+			suffix++
+		}
+		if suffix-end-1 <= 2 {
+			end = suffix
+		}
+	}
+
+	if end < len(s) {
+		if r, _ := utf8.DecodeRuneInString(s[end:]); isWordRune(r) {
+			return ""
+		}
+	}
+	return s[:end]
+}
+
+// normalizeURL lowercases the scheme and host and keeps the path as is
+func normalizeURL(raw string) string {
+	if !strings.Contains(raw, "://") {
+		raw = "http://" + raw
+	}
+
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return ""
+	}
+	u.Scheme = strings.ToLower(u.Scheme)
+	u.Host = strings.ToLower(u.Host)
+	return u.String()
+}
+
+// trimTrailingPunctuation drops sentence punctuation glued to the end of a link
+// a closing bracket is kept when the link itself opened it
+func trimTrailingPunctuation(s string) string {
+	for len(s) > 0 {
+		last := s[len(s)-1]
+		switch last {
+		case '.', ',', '!', '?', ';', ':', '\'', '"':
+			s = s[:len(s)-1]
+			continue
+		case ')':
+			if strings.Count(s, "(") < strings.Count(s, ")") {
+				s = s[:len(s)-1]
+				continue
+			}
+		case ']':
+			if strings.Count(s, "[") < strings.Count(s, "]") {
+				s = s[:len(s)-1]
+				continue
+			}
+		}
+		return s
+	}
+	return s
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.IsMark(r) || r == '_'
+}
+
+func isSigil(r rune) bool {
+	return r == '#' || r == '@' || r == '$'
+}
+
+func isHandleByte(b byte) bool {
+	return isASCIILetter(b) || (b >= '0' && b <= '9') || b == '_'
+}
+
+func isASCIILetter(b byte) bool {
+	return (b >= 'a' && b <= 'z') || (b >= 'A' && b <= 'Z')
+}
+
+// set keeps the first occurrence of every value
+type set map[string]struct{}
+
+func newSet() set {
+	return make(set)
+}
+
+func (s set) add(list *[]string, value string) {
+	if _, ok := s[value]; ok {
+		return
+	}
+	s[value] = struct{}{}
+	*list = append(*list, value)
+}
@@ -0,0 +1,132 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/Udehlee/tweet-stream/models"
+	"github.com/Udehlee/tweet-stream/utils"
+)
+
+var ErrPublishFull = errors.New("publish channel full")
+
+// ErrUnbufferedPublish is returned by NewEmitter for a publish channel without a buffer,
+// room in it cannot be seen before a change is applied
+var ErrUnbufferedPublish = errors.New("publish channel must be buffered")
+
+// publishPoll is how often ApplyWait looks for room in a full publish channel
+const publishPoll = 5 * time.Millisecond
+
+// Emitter wraps tweet changes in TweetEvent envelopes
+// and publishes them with a gap free sequence number
+type Emitter struct {
+	mu       sync.Mutex
+	sequence uint64
+	src      *utils.Source
+	Publish  chan<- *models.TweetEvent
+}
+
+// NewEmitter publishes to a buffered channel, a nil channel publishes nowhere
+func NewEmitter(publish chan<- *models.TweetEvent, src *utils.Source) (*Emitter, error) {
+	if publish != nil && cap(publish) == 0 {
+		return nil, ErrUnbufferedPublish
+	}
+	return &Emitter{
+		src:     src,
+		Publish: publish,
+	}, nil
+}
+
+// Created publishes a CREATED event for the tweet
+func (e *Emitter) Created(tweet *models.Tweet) (*models.TweetEvent, error) {
+	return e.emit(models.EventCreated, tweet, nil)
+}
+
+// Updated publishes an UPDATED event carrying the previous version
+func (e *Emitter) Updated(previous, tweet *models.Tweet) (*models.TweetEvent, error) {
+	return e.emit(models.EventUpdated, tweet, previous)
+}
+
+// Deleted publishes a DELETED event with the last known version of the tweet
+func (e *Emitter) Deleted(tweet *models.Tweet) (*models.TweetEvent, error) {
+	return e.emit(models.EventDeleted, tweet, nil)
+}
+
+// Change applies a tweet change and describes it, previous is only set for updates
+type Change func() (eventType models.EventType, tweet, previous *models.Tweet, err error)
+
+// Apply runs change and publishes its event as one step, so when several writers
+// share the emitter their events reach the stream in the order the changes were made
+// a failed change publishes nothing and returns its error
+func (e *Emitter) Apply(change Change) (*models.TweetEvent, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	eventType, tweet, previous, err := change()
+	if err != nil {
+		return nil, err
+	}
+	return e.publish(eventType, tweet, previous)
+}
+
+// ApplyWait is Apply for writers that must not lose their event
+// it waits until the publish channel has room before running change, so the
+// change is either applied and published or, when ctx ends first, not applied at all
+// the emitter is not held while waiting, other writers keep going
+// an unbuffered Publish never shows room, so nothing is applied until ctx ends
+func (e *Emitter) ApplyWait(ctx context.Context, change Change) (*models.TweetEvent, error) {
+	for {
+		e.mu.Lock()
+		// every send goes through e.mu, so room seen here is still there for publish
+		if e.Publish == nil || len(e.Publish) < cap(e.Publish) {
+			defer e.mu.Unlock()
+
+			eventType, tweet, previous, err := change()
+			if err != nil {
+				return nil, err
+			}
+			return e.publish(eventType, tweet, previous)
+		}
+		e.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(publishPoll):
+		}
+	}
+}
+
+// emit builds the envelope and sends it without blocking
+// the sequence only advances when the event was accepted
+func (e *Emitter) emit(eventType models.EventType, tweet, previous *models.Tweet) (*models.TweetEvent, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return e.publish(eventType, tweet, previous)
+}
+
+// publish sends the event, callers must hold e.mu
+func (e *Emitter) publish(eventType models.EventType, tweet, previous *models.Tweet) (*models.TweetEvent, error) {
+	event := &models.TweetEvent{
+		EventID:   e.src.IDs.NewID(),
+		Type:      eventType,
+		Sequence:  e.sequence + 1,
+		Tweet:     tweet,
+		Previous:  previous,
+		EmittedAt: e.src.Events.Now(),
+	}
+
+	if e.Publish != nil {
+		select {
+		case e.Publish <- event:
+		default:
+			return nil, ErrPublishFull
+		}
+	}
+
+	e.sequence++
+	return event, nil
+}
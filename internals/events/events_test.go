@@ -0,0 +1,77 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Udehlee/tweet-stream/models"
+	"github.com/Udehlee/tweet-stream/utils"
+)
+
+func TestNewEmitterRejectsUnbufferedChannel(t *testing.T) {
+	if _, err := NewEmitter(make(chan *models.TweetEvent), utils.NewSeededSource(1)); !errors.Is(err, ErrUnbufferedPublish) {
+		t.Fatalf("unbuffered channel: got %v, want ErrUnbufferedPublish", err)
+	}
+	if _, err := NewEmitter(nil, utils.NewSeededSource(1)); err != nil {
+		t.Fatalf("nil channel: %v", err)
+	}
+}
+
+func TestApplyWaitDoesNotApplyWhenChannelStaysFull(t *testing.T) {
+	publish := make(chan *models.TweetEvent, 1)
+	e, err := NewEmitter(publish, utils.NewSeededSource(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tweet := &models.Tweet{ID: "A"}
+	created := func() (models.EventType, *models.Tweet, *models.Tweet, error) {
+		return models.EventCreated, tweet, nil, nil
+	}
+	if _, err := e.ApplyWait(context.Background(), created); err != nil {
+		t.Fatalf("first apply: %v", err)
+	}
+
+	applied := false
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err = e.ApplyWait(ctx, func() (models.EventType, *models.Tweet, *models.Tweet, error) {
+		applied = true
+		return models.EventCreated, tweet, nil, nil
+	})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("full channel: got %v, want DeadlineExceeded", err)
+	}
+	if applied {
+		t.Fatal("change ran although its event could not be published")
+	}
+}
+
+func TestApplyWaitPublishesOnceThereIsRoom(t *testing.T) {
+	publish := make(chan *models.TweetEvent, 1)
+	e, err := NewEmitter(publish, utils.NewSeededSource(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	publish <- &models.TweetEvent{}
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		<-publish
+	}()
+
+	event, err := e.ApplyWait(context.Background(), func() (models.EventType, *models.Tweet, *models.Tweet, error) {
+		return models.EventCreated, &models.Tweet{ID: "A"}, nil, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if event.Sequence != 1 {
+		t.Errorf("sequence = %d, want 1", event.Sequence)
+	}
+	if got := <-publish; got != event {
+		t.Error("published event differs from the returned one")
+	}
+}
@@ -0,0 +1,323 @@
+package hub
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/Udehlee/tweet-stream/models"
+	"github.com/Udehlee/tweet-stream/utils"
+)
+
+var (
+	ErrSlowConsumer = errors.New("subscriber disconnected: queue full")
+	ErrHubClosed    = errors.New("hub closed")
+)
+
+// Policy decides what happens when a subscriber queue is full
+type Policy int
+
+const (
+	DropOldest Policy = iota
+	DropNewest
+	Disconnect
+)
+
+func (p Policy) String() string {
+	switch p {
+	case DropOldest:
+		return "drop_oldest"
+	case DropNewest:
+		return "drop_newest"
+	case Disconnect:
+		return "disconnect"
+	}
+	return "unknown"
+}
+
+// ParsePolicy maps a policy name to a Policy
+func ParsePolicy(name string) (Policy, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "", "drop_oldest":
+		return DropOldest, nil
+	case "drop_newest":
+		return DropNewest, nil
+	case "disconnect":
+		return Disconnect, nil
+	}
+	return DropOldest, fmt.Errorf("unknown slow consumer policy %q", name)
+}
+
+// Subscriber is a single consumer registered on the hub
+// with its own bounded queue
+type Subscriber struct {
+	ID      string
+	events  chan *models.TweetEvent
+	done    chan struct{}
+	err     error
+	dropped atomic.Uint64
+}
+
+// Events returns the subscriber queue
+func (s *Subscriber) Events() <-chan *models.TweetEvent {
+	return s.events
+}
+
+// Done is closed once the hub removes the subscriber
+func (s *Subscriber) Done() <-chan struct{} {
+	return s.done
+}
+
+// Err reports why the subscriber was removed, it is only set after Done is closed
+func (s *Subscriber) Err() error {
+	return s.err
+}
+
+// Dropped returns how many events never reached this subscriber
+func (s *Subscriber) Dropped() uint64 {
+	return s.dropped.Load()
+}
+
+// SubscriberStats is a snapshot of a subscriber queue
+type SubscriberStats struct {
+	ID      string
+	Queued  int
+	Dropped uint64
+}
+
+// Hub fans every published event out to all registered subscribers
+type Hub struct {
+	mu          sync.Mutex
+	subscribers map[string]*Subscriber
+	bufferSize  int
+	policy      Policy
+	retention   *RetentionLog
+	closed      bool
+	done        chan struct{}
+}
+
+type Option func(*Hub)
+
+func NewHub(opts ...Option) *Hub {
+	h := &Hub{
+		subscribers: make(map[string]*Subscriber),
+		bufferSize:  50,
+		policy:      DropOldest,
+		done:        make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
+}
+
+// WithBufferSize sets the queue size of every subscriber
+func WithBufferSize(size int) Option {
+	return func(h *Hub) {
+		if size > 0 {
+			h.bufferSize = size
+		}
+	}
+}
+
+// WithPolicy sets the slow consumer policy
+func WithPolicy(policy Policy) Option {
+	return func(h *Hub) {
+		h.policy = policy
+	}
+}
+
+// WithRetentionLog stamps every published event with an offset
+// and lets subscribers resume from it
+func WithRetentionLog(retention *RetentionLog) Option {
+	return func(h *Hub) {
+		h.retention = retention
+	}
+}
+
+// Run publishes every event read from in until the channel closes or ctx is done
+// events already buffered in in when ctx is done are still published before the hub closes
+func (h *Hub) Run(ctx context.Context, in <-chan *models.TweetEvent) {
+	defer h.Close()
+
+	for {
+		select {
+		case <-ctx.Done():
+			h.drain(in)
+			return
+		case event, ok := <-in:
+			if !ok {
+				return
+			}
+			h.Publish(event)
+		}
+	}
+}
+
+// drain publishes what is left in in without waiting for more
+func (h *Hub) drain(in <-chan *models.TweetEvent) {
+	for {
+		select {
+		case event, ok := <-in:
+			if !ok {
+				return
+			}
+			h.Publish(event)
+		default:
+			return
+		}
+	}
+}
+
+// Done is closed once the hub is closed
+func (h *Hub) Done() <-chan struct{} {
+	return h.done
+}
+
+// Subscribe registers a new subscriber queue
+func (h *Hub) Subscribe() *Subscriber {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return h.subscribe()
+}
+
+// SubscribeFrom registers a new subscriber and returns the retained events
+// starting at offset, the backlog and the live queue neither overlap nor leave a gap
+func (h *Hub) SubscribeFrom(offset uint64) (*Subscriber, []*models.TweetEvent, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.retention == nil {
+		return nil, nil, errors.New("hub: resuming requires a retention log")
+	}
+
+	backlog, err := h.retention.Since(offset)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return h.subscribe(), backlog, nil
+}
+
+// subscribe creates and registers a subscriber, callers must hold h.mu
+func (h *Hub) subscribe() *Subscriber {
+	sub := &Subscriber{
+		ID:     utils.GenerateID(),
+		events: make(chan *models.TweetEvent, h.bufferSize),
+		done:   make(chan struct{}),
+	}
+
+	if h.closed {
+		sub.err = ErrHubClosed
+		close(sub.done)
+		return sub
+	}
+
+	h.subscribers[sub.ID] = sub
+	log.Printf("hub: subscriber %s registered (%d active)", sub.ID, len(h.subscribers))
+	return sub
+}
+
+// Unsubscribe removes the subscriber, it is safe to call more than once
+func (h *Hub) Unsubscribe(sub *Subscriber) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.remove(sub, nil)
+}
+
+// Publish records the event in the retention log and delivers it
+// to every subscriber applying the slow consumer policy to full queues
+func (h *Hub) Publish(event *models.TweetEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.retention != nil {
+		if err := h.retention.Append(event); err != nil {
+			log.Println("hub:", err)
+		}
+	}
+
+	for _, sub := range h.subscribers {
+		h.deliver(sub, event)
+	}
+}
+
+// Stats returns a snapshot of every subscriber queue
+func (h *Hub) Stats() []SubscriberStats {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	stats := make([]SubscriberStats, 0, len(h.subscribers))
+	for _, sub := range h.subscribers {
+		stats = append(stats, SubscriberStats{
+			ID:      sub.ID,
+			Queued:  len(sub.events),
+			Dropped: sub.Dropped(),
+		})
+	}
+	return stats
+}
+
+// Close removes all subscribers and rejects new ones
+// events already queued stay readable from each subscriber's Events
+func (h *Hub) Close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.closed {
+		return
+	}
+	h.closed = true
+	defer close(h.done)
+
+	for _, sub := range h.subscribers {
+		h.remove(sub, ErrHubClosed)
+	}
+}
+
+// deliver sends a single event to sub, callers must hold h.mu
+func (h *Hub) deliver(sub *Subscriber, event *models.TweetEvent) {
+	for {
+		select {
+		case sub.events <- event:
+			return
+		default:
+		}
+
+		switch h.policy {
+		case DropNewest:
+			sub.dropped.Add(1)
+			return
+
+		case Disconnect:
+			sub.dropped.Add(1)
+			h.remove(sub, ErrSlowConsumer)
+			return
+
+		default:
+			select {
+			case <-sub.events:
+				sub.dropped.Add(1)
+			default:
+			}
+		}
+	}
+}
+
+// remove unregisters sub and closes its done channel, callers must hold h.mu
+func (h *Hub) remove(sub *Subscriber, reason error) {
+	if _, ok := h.subscribers[sub.ID]; !ok {
+		return
+	}
+
+	delete(h.subscribers, sub.ID)
+	sub.err = reason
+	close(sub.done)
+	log.Printf("hub: subscriber %s removed (dropped=%d, active=%d)", sub.ID, sub.Dropped(), len(h.subscribers))
+}
@@ -0,0 +1,257 @@
+package hub
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/Udehlee/tweet-stream/models"
+)
+
+// OffsetExpiredError is returned when a requested offset
+// is older than anything the log still retains
+type OffsetExpiredError struct {
+	Requested uint64
+	Oldest    uint64
+}
+
+func (e *OffsetExpiredError) Error() string {
+	return fmt.Sprintf("offset %d has aged out of the retention log, oldest retained offset is %d", e.Requested, e.Oldest)
+}
+
+// OffsetAheadError is returned when a requested offset
+// has not been assigned yet
+type OffsetAheadError struct {
+	Requested uint64
+	Next      uint64
+}
+
+func (e *OffsetAheadError) Error() string {
+	return fmt.Sprintf("offset %d is ahead of the retention log, next offset is %d", e.Requested, e.Next)
+}
+
+// firstOffset is the offset of the first event a log ever appends
+const firstOffset = 1
+
+// RetentionLog keeps the most recent events in a ring buffer
+// and stamps every appended event with a monotonically increasing offset
+// when a spool file is configured every event is also appended to it
+// so the log survives restarts
+type RetentionLog struct {
+	mu       sync.Mutex
+	events   []*models.TweetEvent
+	head     int // index of the oldest event
+	count    int
+	next     uint64
+	path     string
+	spool    *os.File
+	writer   *bufio.Writer
+	spooled  int // events written to the spool since the last compaction
+	capacity int
+}
+
+// NewRetentionLog creates an in-memory log holding up to capacity events
+func NewRetentionLog(capacity int) *RetentionLog {
+	if capacity <= 0 {
+		capacity = 1
+	}
+	return &RetentionLog{
+		events:   make([]*models.TweetEvent, capacity),
+		next:     firstOffset,
+		capacity: capacity,
+	}
+}
+
+// OpenRetentionLog creates a log backed by the spool file at path
+// retained events are loaded from the file before new ones are accepted
+func OpenRetentionLog(capacity int, path string) (*RetentionLog, error) {
+	l := NewRetentionLog(capacity)
+	l.path = path
+
+	if err := l.load(); err != nil {
+		return nil, err
+	}
+
+	if err := l.compact(); err != nil {
+		return nil, err
+	}
+
+	return l, nil
+}
+
+// Append stamps the event with the next offset and retains it
+func (l *RetentionLog) Append(event *models.TweetEvent) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	event.Offset = l.next
+	l.next++
+	l.push(event)
+
+	if l.writer == nil {
+		return nil
+	}
+
+	if err := l.writeSpool(event); err != nil {
+		return fmt.Errorf("retention log: failed to spool event %d: %w", event.Offset, err)
+	}
+
+	l.spooled++
+	if l.spooled >= 2*l.capacity {
+		return l.compact()
+	}
+	return nil
+}
+
+// Since returns every retained event with an offset of at least offset
+// offsets before the first one were never assigned, so they read as the
+// start of the log and an empty log answers them with no events
+func (l *RetentionLog) Since(offset uint64) ([]*models.TweetEvent, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if offset > l.next {
+		return nil, &OffsetAheadError{Requested: offset, Next: l.next}
+	}
+
+	from := max(offset, firstOffset)
+	oldest := l.oldest()
+	if from < oldest {
+		return nil, &OffsetExpiredError{Requested: offset, Oldest: oldest}
+	}
+
+	skip := int(from - oldest)
+	result := make([]*models.TweetEvent, 0, l.count-skip)
+	for i := skip; i < l.count; i++ {
+		result = append(result, l.events[(l.head+i)%l.capacity])
+	}
+	return result, nil
+}
+
+// Next returns the offset the next appended event will receive
+func (l *RetentionLog) Next() uint64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.next
+}
+
+// Close flushes and closes the spool file
+func (l *RetentionLog) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.closeSpool()
+}
+
+// oldest returns the offset of the oldest retained event
+// or the next offset when the log is empty, callers must hold l.mu
+func (l *RetentionLog) oldest() uint64 {
+	if l.count == 0 {
+		return l.next
+	}
+	return l.events[l.head].Offset
+}
+
+// push adds the event to the ring, overwriting the oldest one when full
+func (l *RetentionLog) push(event *models.TweetEvent) {
+	if l.count < l.capacity {
+		l.events[(l.head+l.count)%l.capacity] = event
+		l.count++
+		return
+	}
+	l.events[l.head] = event
+	l.head = (l.head + 1) % l.capacity
+}
+
+// load reads retained events back from the spool file
+func (l *RetentionLog) load() error {
+	file, err := os.Open(l.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("retention log: failed to open spool: %w", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		var event models.TweetEvent
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			// a torn write at the tail is expected after a crash
+			break
+		}
+		if event.Offset < l.next {
+			continue
+		}
+		l.push(&event)
+		l.next = event.Offset + 1
+	}
+
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("retention log: failed to read spool: %w", err)
+	}
+	return nil
+}
+
+// compact rewrites the spool so it only holds the retained events
+func (l *RetentionLog) compact() error {
+	if err := l.closeSpool(); err != nil {
+		return err
+	}
+
+	tmp := l.path + ".tmp"
+	file, err := os.Create(tmp)
+	if err != nil {
+		return fmt.Errorf("retention log: failed to create spool: %w", err)
+	}
+
+	l.spool = file
+	l.writer = bufio.NewWriter(file)
+	for i := 0; i < l.count; i++ {
+		if err := l.writeSpool(l.events[(l.head+i)%l.capacity]); err != nil {
+			return fmt.Errorf("retention log: failed to compact spool: %w", err)
+		}
+	}
+
+	if err := os.Rename(tmp, l.path); err != nil {
+		return fmt.Errorf("retention log: failed to replace spool: %w", err)
+	}
+
+	l.spooled = 0
+	return nil
+}
+
+// writeSpool appends a single event as a JSON line and flushes it
+func (l *RetentionLog) writeSpool(event *models.TweetEvent) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+
+	if _, err := l.writer.Write(data); err != nil {
+		return err
+	}
+	return l.writer.Flush()
+}
+
+// closeSpool flushes and closes the current spool file if any
+func (l *RetentionLog) closeSpool() error {
+	if l.spool == nil {
+		return nil
+	}
+
+	flushErr := l.writer.Flush()
+	closeErr := l.spool.Close()
+	l.spool, l.writer = nil, nil
+
+	if flushErr != nil {
+		return flushErr
+	}
+	return closeErr
+}
@@ -0,0 +1,166 @@
+package hub
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/Udehlee/tweet-stream/models"
+)
+
+// appendN appends n events and returns the log
+func appendN(t *testing.T, l *RetentionLog, n int) *RetentionLog {
+	t.Helper()
+	for i := 0; i < n; i++ {
+		if err := l.Append(&models.TweetEvent{EventID: "e"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return l
+}
+
+func offsets(events []*models.TweetEvent) []uint64 {
+	out := make([]uint64, len(events))
+	for i, event := range events {
+		out[i] = event.Offset
+	}
+	return out
+}
+
+func TestRetentionLogSince(t *testing.T) {
+	tests := []struct {
+		name     string
+		capacity int
+		appended int
+		offset   uint64
+		want     []uint64
+		expired  bool
+		ahead    bool
+	}{
+		{name: "empty log from 0", capacity: 3, offset: 0, want: []uint64{}},
+		{name: "empty log from first offset", capacity: 3, offset: 1, want: []uint64{}},
+		{name: "empty log ahead", capacity: 3, offset: 2, ahead: true},
+		{name: "from 0 before wrap", capacity: 3, appended: 2, offset: 0, want: []uint64{1, 2}},
+		{name: "from the middle", capacity: 3, appended: 3, offset: 2, want: []uint64{2, 3}},
+		{name: "from next", capacity: 3, appended: 3, offset: 4, want: []uint64{}},
+		{name: "ahead of next", capacity: 3, appended: 3, offset: 5, ahead: true},
+		{name: "after wrap", capacity: 3, appended: 7, offset: 5, want: []uint64{5, 6, 7}},
+		{name: "after wrap from oldest", capacity: 3, appended: 7, offset: 6, want: []uint64{6, 7}},
+		{name: "aged out after wrap", capacity: 3, appended: 7, offset: 4, expired: true},
+		{name: "from 0 after wrap", capacity: 3, appended: 7, offset: 0, expired: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := appendN(t, NewRetentionLog(tt.capacity), tt.appended)
+			got, err := l.Since(tt.offset)
+
+			var expired *OffsetExpiredError
+			var ahead *OffsetAheadError
+			switch {
+			case tt.expired:
+				if !errors.As(err, &expired) {
+					t.Fatalf("got %v, want OffsetExpiredError", err)
+				}
+				if expired.Requested != tt.offset {
+					t.Errorf("requested = %d, want %d", expired.Requested, tt.offset)
+				}
+			case tt.ahead:
+				if !errors.As(err, &ahead) {
+					t.Fatalf("got %v, want OffsetAheadError", err)
+				}
+			default:
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !slices.Equal(offsets(got), tt.want) {
+					t.Errorf("offsets = %v, want %v", offsets(got), tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestRetentionLogReopensAfterTornWrite(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "retention.ndjson")
+
+	l, err := OpenRetentionLog(3, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	appendN(t, l, 5)
+	if err := l.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// a crash in the middle of a write leaves half a line at the tail
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := file.WriteString(`{"EventID":"torn","Offs`); err != nil {
+		t.Fatal(err)
+	}
+	file.Close()
+
+	reopened, err := OpenRetentionLog(3, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reopened.Close()
+
+	if next := reopened.Next(); next != 6 {
+		t.Errorf("next = %d, want 6", next)
+	}
+	got, err := reopened.Since(3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []uint64{3, 4, 5}; !slices.Equal(offsets(got), want) {
+		t.Errorf("offsets = %v, want %v", offsets(got), want)
+	}
+
+	// offsets carry on from the spool and compaction drops the torn tail
+	appendN(t, reopened, 1)
+	got, err = reopened.Since(4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []uint64{4, 5, 6}; !slices.Equal(offsets(got), want) {
+		t.Errorf("offsets after append = %v, want %v", offsets(got), want)
+	}
+}
+
+func TestRetentionLogCompactsSpool(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "retention.ndjson")
+
+	l, err := OpenRetentionLog(2, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// a compaction runs after every 2*capacity appends
+	appendN(t, l, 4)
+	if err := l.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lines := bytes.Count(data, []byte("\n")); lines != 2 {
+		t.Errorf("spool holds %d events after compaction, want 2", lines)
+	}
+
+	reopened, err := OpenRetentionLog(2, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reopened.Close()
+	if _, err := reopened.Since(2); !errors.As(err, new(*OffsetExpiredError)) {
+		t.Errorf("offset 2 after compaction: got %v, want OffsetExpiredError", err)
+	}
+}
@@ -0,0 +1,83 @@
+// Package lifecycle stops the components of the service in order within a deadline
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// StopFunc stops one component, it should give up once ctx is done
+type StopFunc func(ctx context.Context) error
+
+type step struct {
+	name string
+	stop StopFunc
+}
+
+// Shutdown runs stop steps one after another in the order they were added
+type Shutdown struct {
+	steps  []step
+	logger *zerolog.Logger
+}
+
+func NewShutdown(logger *zerolog.Logger) *Shutdown {
+	return &Shutdown{logger: logger}
+}
+
+// Add appends a step, components should be added upstream first
+func (s *Shutdown) Add(name string, stop StopFunc) {
+	s.steps = append(s.steps, step{name: name, stop: stop})
+}
+
+// Run stops every component within timeout
+// a step that fails or runs past the deadline does not keep later steps from running,
+// they get an expired context and should only release what they hold
+func (s *Shutdown) Run(timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	var errs []error
+	for _, st := range s.steps {
+		start := time.Now()
+		if err := s.runStep(ctx, st); err != nil {
+			s.logger.Error().Err(err).Msgf("shutdown: %s failed after %s", st.name, time.Since(start).Round(time.Millisecond))
+			errs = append(errs, fmt.Errorf("%s: %w", st.name, err))
+			continue
+		}
+		s.logger.Info().Msgf("shutdown: %s stopped in %s", st.name, time.Since(start).Round(time.Millisecond))
+	}
+	return errors.Join(errs...)
+}
+
+// runStep returns when the step does or when the deadline passes
+// a step left running past the deadline is abandoned
+func (s *Shutdown) runStep(ctx context.Context, st step) error {
+	done := make(chan error, 1)
+	go func() {
+		done <- st.stop(ctx)
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return fmt.Errorf("still running at the shutdown deadline: %w", ctx.Err())
+	}
+}
+
+// WaitFor cancels a component's context and waits for it to report done
+func WaitFor(cancel context.CancelFunc, done <-chan struct{}) StopFunc {
+	return func(ctx context.Context) error {
+		cancel()
+		select {
+		case <-done:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
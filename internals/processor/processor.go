@@ -7,43 +7,59 @@ import (
 	"io"
 	"log"
 	"math"
-	"math/rand/v2"
+	"sync"
 	"time"
 
 	"github.com/Udehlee/tweet-stream/gapi"
 	"github.com/Udehlee/tweet-stream/models"
 	pb "github.com/Udehlee/tweet-stream/pb"
+	"github.com/Udehlee/tweet-stream/utils"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
 )
 
 type StreamProcessor struct {
 	grpcTarget    string
-	aggregateChan chan<- *models.Tweet
+	aggregateChan chan<- *models.TweetEvent
 	dialOpts      []grpc.DialOption
 	Timeout       time.Duration
+	lastOffset    uint64 // offset of the last event forwarded, 0 before the first one
+	rand          *utils.Random
+	stopping      chan struct{} // closed by Stop, Run returns once the current stream ends
+	stopOnce      sync.Once
+	done          chan struct{} // closed when Run returns
 }
 
-func NewProcessor(grpcTarget string, aggChan chan<- *models.Tweet) *StreamProcessor {
+func NewProcessor(grpcTarget string, aggChan chan<- *models.TweetEvent, r *utils.Random) *StreamProcessor {
 	return &StreamProcessor{
 		grpcTarget:    grpcTarget,
 		aggregateChan: aggChan,
 		dialOpts:      []grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())},
 		Timeout:       0,
+		rand:          r,
+		stopping:      make(chan struct{}),
+		done:          make(chan struct{}),
 	}
 }
 
 // Run connects to gRPC and starts processing
 func (p *StreamProcessor) Run(ctx context.Context) error {
+	defer close(p.done)
 	attempt := 0 //keep track of how many times we've retried
 
 	for {
+		if p.isStopping() {
+			log.Println("processor: stopped")
+			return nil
+		}
 		attempt++
 
 		conn, err := grpc.NewClient(p.grpcTarget, grpc.WithTransportCredentials(insecure.NewCredentials()))
 		if err != nil {
 			logFailure("grpc dial failed", err)
-			if err := retry(ctx, attempt); err != nil {
+			if err := p.retry(ctx, attempt); err != nil {
 				return err
 			}
 			continue
@@ -53,20 +69,24 @@ func (p *StreamProcessor) Run(ctx context.Context) error {
 		streamCtx, cancel := context.WithCancel(ctx)
 		client := pb.NewTweetServiceClient(conn)
 
-		stream, err := client.StreamTweets(streamCtx, &pb.Empty{})
+		stream, err := client.StreamTweets(streamCtx, p.streamRequest())
 		if err != nil {
 			logFailure("failed to create stream", err)
 			cleanup(cancel, conn)
-			if err := retry(ctx, 1); err != nil {
+			if err := p.retry(ctx, 1); err != nil {
 				return err
 			}
 			continue
 		}
 
 		if err := p.processStream(stream); err != nil {
+			if status.Code(err) == codes.OutOfRange {
+				log.Printf("processor: cannot resume after offset %d, events were lost: %v", p.lastOffset, err)
+				p.lastOffset = 0
+			}
 			logFailure("process stream", err)
 			cleanup(cancel, conn)
-			if err := retry(ctx, 1); err != nil {
+			if err := p.retry(ctx, 1); err != nil {
 				return err
 			}
 			continue
@@ -77,7 +97,30 @@ func (p *StreamProcessor) Run(ctx context.Context) error {
 	}
 }
 
-// processStream recieves and forwards tweets in the model tweet format
+// Stop lets the current stream run until the server ends it, so events the
+// server still had queued reach the aggregator, and then stops Run without reconnecting
+// the caller should cancel Run's context when ctx is done first
+func (p *StreamProcessor) Stop(ctx context.Context) error {
+	p.stopOnce.Do(func() { close(p.stopping) })
+
+	select {
+	case <-p.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (p *StreamProcessor) isStopping() bool {
+	select {
+	case <-p.stopping:
+		return true
+	default:
+		return false
+	}
+}
+
+// processStream recieves and forwards tweet events in the model format
 func (p *StreamProcessor) processStream(stream pb.TweetService_StreamTweetsClient) error {
 	converter := gapi.NewConverter()
 
@@ -91,21 +134,37 @@ func (p *StreamProcessor) processStream(stream pb.TweetService_StreamTweetsClien
 			return err
 		}
 
-		modelTweet := converter.Convert(msg).(*models.Tweet)
-		p.forwardStreams(modelTweet)
+		modelEvent := converter.Convert(msg).(*models.TweetEvent)
+		if modelEvent.Offset != 0 && modelEvent.Offset <= p.lastOffset {
+			continue // already forwarded before the reconnect
+		}
+
+		p.forwardStreams(modelEvent)
+		p.lastOffset = modelEvent.Offset
 	}
 }
 
-// forwardStreams forwards model tweets to the aggregator channel
-func (p *StreamProcessor) forwardStreams(tweet *models.Tweet) {
+// streamRequest resumes right after the last forwarded offset
+// so events emitted while reconnecting are replayed
+func (p *StreamProcessor) streamRequest() *pb.StreamRequest {
+	req := &pb.StreamRequest{}
+	if p.lastOffset > 0 {
+		next := p.lastOffset + 1
+		req.ResumeFromOffset = &next
+	}
+	return req
+}
+
+// forwardStreams forwards model tweet events to the aggregator channel
+func (p *StreamProcessor) forwardStreams(event *models.TweetEvent) {
 	if p.aggregateChan == nil {
 		return
 	}
 
 	select {
-	case p.aggregateChan <- tweet:
+	case p.aggregateChan <- event:
 	default:
-		log.Println("processor: aggregateChan full, dropping tweet")
+		log.Println("processor: aggregateChan full, dropping tweet event")
 	}
 }
 
@@ -123,7 +182,7 @@ func cleanup(cancel context.CancelFunc, conn *grpc.ClientConn) {
 }
 
 // retry waits before the next retry attempt using exponential backoff
-func retry(ctx context.Context, attempt int) error {
+func (p *StreamProcessor) retry(ctx context.Context, attempt int) error {
 	const (
 		MaxRetries = 10
 		BaseDelay  = 500 * time.Millisecond
@@ -139,13 +198,15 @@ func retry(ctx context.Context, attempt int) error {
 		delay = MaxDelay
 	}
 
-	jitter := time.Duration(rand.Float64() * float64(delay) * 0.2)
+	jitter := time.Duration(p.rand.Float64() * float64(delay) * 0.2)
 	delay += jitter
 
-	// Wait or exit early if context is cancelled
+	// Wait or exit early if context is cancelled or the processor is stopping
 	select {
 	case <-time.After(delay):
 		return nil
+	case <-p.stopping:
+		return nil
 	case <-ctx.Done():
 		return ctx.Err()
 	}
@@ -0,0 +1,178 @@
+package scenario
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/Udehlee/tweet-stream/internals/data/generator"
+	"github.com/Udehlee/tweet-stream/utils"
+	"github.com/rs/zerolog"
+)
+
+// idleCheck is how often a phase with a zero rate looks at its rate again
+const idleCheck = 100 * time.Millisecond
+
+// Generator is the part of generator.GeneratorService the runner drives
+type Generator interface {
+	RunOperation(ctx context.Context, name string) error
+	PostWithHashtags(ctx context.Context, tags ...string)
+}
+
+// Runner drives a generator through the phases of a scenario
+type Runner struct {
+	scenario *Scenario
+	gen      Generator
+	rand     *utils.Random
+	logger   *zerolog.Logger
+}
+
+func NewRunner(s *Scenario, gen Generator, r *utils.Random, logger *zerolog.Logger) *Runner {
+	return &Runner{
+		scenario: s,
+		gen:      gen,
+		rand:     r,
+		logger:   logger,
+	}
+}
+
+// Run plays every phase in order, forever when the scenario loops
+// it returns when the scenario ends or ctx is done
+func (r *Runner) Run(ctx context.Context) {
+	for {
+		for i := range r.scenario.Phases {
+			phase := &r.scenario.Phases[i]
+			r.logger.Info().Msgf("scenario %s: phase %s (%s) for %s", r.scenario.Name, phase.Name, phase.Type, time.Duration(phase.Duration))
+
+			if !r.runPhase(ctx, phase) {
+				return
+			}
+		}
+
+		if !r.scenario.Loop {
+			r.logger.Info().Msgf("scenario %s finished", r.scenario.Name)
+			return
+		}
+	}
+}
+
+// runPhase schedules operations for a single phase
+// it reports false when ctx was cancelled
+func (r *Runner) runPhase(ctx context.Context, phase *Phase) bool {
+	mix := newMix(phase.Mix, r.scenario.Mix)
+	start := time.Now()
+	end := start.Add(time.Duration(phase.Duration))
+	next := start
+
+	for {
+		now := time.Now()
+		if !now.Before(end) {
+			return true
+		}
+
+		rate := phase.RateAt(now.Sub(start))
+		if rate <= 0 {
+			next = now.Add(idleCheck)
+		} else {
+			next = next.Add(r.interval(phase, rate))
+			// when operations run slower than the schedule, catch up without sleeping
+			// but never build up more than a second of backlog
+			if behind := now.Sub(next); behind > time.Second {
+				next = now
+			}
+		}
+
+		if next.After(end) {
+			next = end
+		}
+
+		if wait := time.Until(next); wait > 0 {
+			timer := time.NewTimer(wait)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return false
+			case <-timer.C:
+			}
+		} else if ctx.Err() != nil {
+			return false
+		}
+
+		if rate > 0 && time.Now().Before(end) {
+			r.runOperation(ctx, phase, mix.pick(r.rand))
+		}
+	}
+}
+
+// interval returns the gap before the next operation
+// poisson phases draw exponential gaps, the others are evenly spaced
+func (r *Runner) interval(phase *Phase, rate float64) time.Duration {
+	seconds := 1 / rate
+	if phase.Type == Poisson {
+		seconds = r.rand.ExpFloat64() / rate
+	}
+	return time.Duration(seconds * float64(time.Second))
+}
+
+// runOperation runs one operation, storm phases tag a share of their posts
+func (r *Runner) runOperation(ctx context.Context, phase *Phase, op string) {
+	if phase.Type == Storm && op == generator.OpPost && r.rand.Float64() < phase.StormShare {
+		r.gen.PostWithHashtags(ctx, phase.Hashtag)
+		return
+	}
+
+	if err := r.gen.RunOperation(ctx, op); err != nil {
+		r.logger.Info().Err(err).Msg("scenario operation failed")
+	}
+}
+
+// mix picks operations according to their weights
+type mix struct {
+	ops        []string
+	cumulative []float64
+}
+
+// newMix uses the first non-empty weights, or an even mix when both are empty
+func newMix(weights, fallback map[string]float64) *mix {
+	if len(weights) == 0 {
+		weights = fallback
+	}
+	if len(weights) == 0 {
+		weights = make(map[string]float64, len(generator.OperationNames))
+		for _, op := range generator.OperationNames {
+			weights[op] = 1
+		}
+	}
+
+	m := &mix{}
+	for op := range weights {
+		m.ops = append(m.ops, op)
+	}
+	sort.Strings(m.ops) // stable order keeps seeded runs reproducible
+
+	var total float64
+	for _, op := range m.ops {
+		total += weights[op]
+		m.cumulative = append(m.cumulative, total)
+	}
+	return m
+}
+
+func (m *mix) pick(r *utils.Random) string {
+	target := r.Float64() * m.cumulative[len(m.cumulative)-1]
+	i := sort.SearchFloat64s(m.cumulative, target)
+	if i >= len(m.ops) {
+		i = len(m.ops) - 1
+	}
+	return m.ops[i]
+}
+
+// knownOperation reports whether op is a generator operation
+func knownOperation(op string) bool {
+	for _, name := range generator.OperationNames {
+		if name == op {
+			return true
+		}
+	}
+	return false
+}
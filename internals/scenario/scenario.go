@@ -0,0 +1,206 @@
+package scenario
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Phase types
+const (
+	Steady  = "steady"  // constant rate
+	Ramp    = "ramp"    // linear change from start_rate to end_rate
+	Burst   = "burst"   // rate with a spike of peak_rate lasting burst_length every period
+	Diurnal = "diurnal" // rate plus a sine wave of amplitude over period
+	Poisson = "poisson" // random arrivals averaging rate
+	Storm   = "storm"   // viral hashtag storm, storm_share of posts carry hashtag
+)
+
+// Duration accepts Go duration strings like "30s" or "2m" in scenario files
+type Duration time.Duration
+
+func (d *Duration) UnmarshalYAML(node *yaml.Node) error {
+	return d.parse(node.Value)
+}
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("duration must be a string like \"30s\": %w", err)
+	}
+	return d.parse(s)
+}
+
+func (d *Duration) parse(s string) error {
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(v)
+	return nil
+}
+
+// Scenario is a sequence of traffic phases
+// Mix is the default operation mix for phases without their own
+type Scenario struct {
+	Name   string             `yaml:"name" json:"name"`
+	Loop   bool               `yaml:"loop" json:"loop"`
+	Mix    map[string]float64 `yaml:"mix" json:"mix"`
+	Phases []Phase            `yaml:"phases" json:"phases"`
+}
+
+// Phase describes the traffic shape for a period of time
+// rates are operations per second
+type Phase struct {
+	Name        string             `yaml:"name" json:"name"`
+	Type        string             `yaml:"type" json:"type"`
+	Duration    Duration           `yaml:"duration" json:"duration"`
+	Rate        float64            `yaml:"rate" json:"rate"`
+	StartRate   float64            `yaml:"start_rate" json:"start_rate"`
+	EndRate     float64            `yaml:"end_rate" json:"end_rate"`
+	PeakRate    float64            `yaml:"peak_rate" json:"peak_rate"`
+	BurstLength Duration           `yaml:"burst_length" json:"burst_length"`
+	Amplitude   float64            `yaml:"amplitude" json:"amplitude"`
+	Period      Duration           `yaml:"period" json:"period"`
+	Hashtag     string             `yaml:"hashtag" json:"hashtag"`
+	StormShare  float64            `yaml:"storm_share" json:"storm_share"`
+	Mix         map[string]float64 `yaml:"mix" json:"mix"`
+}
+
+// Load reads a scenario from a .json file or a YAML file
+func Load(path string) (*Scenario, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read scenario: %w", err)
+	}
+
+	var s Scenario
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.DisallowUnknownFields()
+		err = dec.Decode(&s)
+	} else {
+		dec := yaml.NewDecoder(bytes.NewReader(data))
+		dec.KnownFields(true)
+		err = dec.Decode(&s)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode scenario %s: %w", path, err)
+	}
+
+	if err := s.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid scenario %s: %w", path, err)
+	}
+	return &s, nil
+}
+
+// Validate checks every phase and operation mix
+func (s *Scenario) Validate() error {
+	if len(s.Phases) == 0 {
+		return errors.New("scenario has no phases")
+	}
+
+	var errs []error
+	if err := validateMix(s.Mix); err != nil {
+		errs = append(errs, fmt.Errorf("mix: %w", err))
+	}
+
+	for i, p := range s.Phases {
+		if err := p.validate(); err != nil {
+			errs = append(errs, fmt.Errorf("phase %d (%s): %w", i+1, p.Name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// validate checks the fields the phase type relies on
+func (p *Phase) validate() error {
+	var errs []error
+	if p.Duration <= 0 {
+		errs = append(errs, errors.New("duration must be positive"))
+	}
+	if p.Rate < 0 || p.StartRate < 0 || p.EndRate < 0 || p.PeakRate < 0 {
+		errs = append(errs, errors.New("rates cannot be negative"))
+	}
+
+	switch p.Type {
+	case Steady, Poisson:
+	case Ramp:
+		if p.StartRate == 0 && p.EndRate == 0 {
+			errs = append(errs, errors.New("ramp needs start_rate or end_rate"))
+		}
+	case Burst:
+		if p.Period <= 0 || p.BurstLength <= 0 || p.BurstLength > p.Period {
+			errs = append(errs, errors.New("burst needs 0 < burst_length <= period"))
+		}
+	case Diurnal:
+		if p.Period <= 0 {
+			errs = append(errs, errors.New("diurnal needs a period"))
+		}
+	case Storm:
+		if strings.TrimPrefix(p.Hashtag, "#") == "" {
+			errs = append(errs, errors.New("storm needs a hashtag"))
+		}
+		if p.StormShare < 0 || p.StormShare > 1 {
+			errs = append(errs, errors.New("storm_share must be between 0 and 1"))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("unknown phase type %q", p.Type))
+	}
+
+	if err := validateMix(p.Mix); err != nil {
+		errs = append(errs, fmt.Errorf("mix: %w", err))
+	}
+	return errors.Join(errs...)
+}
+
+// RateAt returns the target operations per second elapsed into the phase
+func (p *Phase) RateAt(elapsed time.Duration) float64 {
+	var rate float64
+	switch p.Type {
+	case Ramp:
+		progress := float64(elapsed) / float64(p.Duration)
+		rate = p.StartRate + (p.EndRate-p.StartRate)*math.Min(progress, 1)
+	case Burst:
+		rate = p.Rate
+		if elapsed%time.Duration(p.Period) < time.Duration(p.BurstLength) {
+			rate = p.PeakRate
+		}
+	case Diurnal:
+		phase := 2 * math.Pi * float64(elapsed) / float64(p.Period)
+		rate = p.Rate + p.Amplitude*math.Sin(phase)
+	default:
+		rate = p.Rate
+	}
+	return math.Max(rate, 0)
+}
+
+// validateMix checks that mix weights are usable
+func validateMix(mix map[string]float64) error {
+	if len(mix) == 0 {
+		return nil
+	}
+
+	var total float64
+	for op, w := range mix {
+		if !knownOperation(op) {
+			return fmt.Errorf("unknown operation %q", op)
+		}
+		if w < 0 {
+			return fmt.Errorf("weight of %q cannot be negative", op)
+		}
+		total += w
+	}
+	if total == 0 {
+		return errors.New("weights add up to zero")
+	}
+	return nil
+}
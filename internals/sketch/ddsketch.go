@@ -0,0 +1,359 @@
+// Package sketch holds mergeable quantile sketches
+package sketch
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+)
+
+const (
+	DefaultRelativeAccuracy = 0.01
+	DefaultMaxBins          = 2048
+
+	// values at or below minIndexable land in the zero bucket
+	minIndexable = 1e-9
+)
+
+// DDSketch estimates quantiles with a bounded relative error
+// values are bucketed on a logarithmic scale so two sketches with the
+// same accuracy merge by adding their bucket counts
+// see Masson et al, DDSketch: a fast and fully-mergeable quantile sketch
+type DDSketch struct {
+	alpha   float64
+	gamma   float64
+	logG    float64
+	maxBins int
+
+	bins  map[int]uint64
+	zero  uint64
+	count uint64
+	sum   float64
+	min   float64
+	max   float64
+}
+
+// New returns a sketch whose quantiles are within alpha relative error
+func New(alpha float64) *DDSketch {
+	if alpha <= 0 || alpha >= 1 {
+		alpha = DefaultRelativeAccuracy
+	}
+	gamma := (1 + alpha) / (1 - alpha)
+	return &DDSketch{
+		alpha:   alpha,
+		gamma:   gamma,
+		logG:    math.Log(gamma),
+		maxBins: DefaultMaxBins,
+		bins:    make(map[int]uint64),
+		min:     math.Inf(1),
+		max:     math.Inf(-1),
+	}
+}
+
+// Add records a non negative value, negative values count as zero
+func (s *DDSketch) Add(v float64) {
+	s.AddN(v, 1)
+}
+
+// AddN records v n times
+func (s *DDSketch) AddN(v float64, n uint64) {
+	if n == 0 || math.IsNaN(v) {
+		return
+	}
+	if v < 0 {
+		v = 0
+	}
+
+	if v <= minIndexable {
+		s.zero += n
+	} else {
+		s.bins[s.index(v)] += n
+		s.collapse()
+	}
+
+	s.count += n
+	s.sum += v * float64(n)
+	s.min = math.Min(s.min, v)
+	s.max = math.Max(s.max, v)
+}
+
+// Quantile returns the estimated value at q in [0, 1], 0 when the sketch is empty
+func (s *DDSketch) Quantile(q float64) float64 {
+	if s.count == 0 || q < 0 || q > 1 {
+		return 0
+	}
+	if q == 0 {
+		return s.min
+	}
+	if q == 1 {
+		return s.max
+	}
+
+	rank := uint64(q * float64(s.count-1))
+	if rank < s.zero {
+		return 0
+	}
+
+	seen := s.zero
+	for _, i := range s.indexes() {
+		seen += s.bins[i]
+		if seen > rank {
+			// clamp so the estimate never leaves the observed range
+			return math.Max(s.min, math.Min(s.max, s.value(i)))
+		}
+	}
+	return s.max
+}
+
+// Merge adds the counts of other, both sketches must share the same accuracy
+func (s *DDSketch) Merge(other *DDSketch) error {
+	if other == nil || other.count == 0 {
+		return nil
+	}
+	if other.alpha != s.alpha {
+		return fmt.Errorf("cannot merge sketches with accuracy %g and %g", s.alpha, other.alpha)
+	}
+
+	for i, n := range other.bins {
+		s.bins[i] += n
+	}
+	s.collapse()
+
+	s.zero += other.zero
+	s.count += other.count
+	s.sum += other.sum
+	s.min = math.Min(s.min, other.min)
+	s.max = math.Max(s.max, other.max)
+	return nil
+}
+
+// Clone returns an independent copy, later adds to either sketch do not show in the other
+func (s *DDSketch) Clone() *DDSketch {
+	clone := *s
+	clone.bins = make(map[int]uint64, len(s.bins))
+	for i, n := range s.bins {
+		clone.bins[i] = n
+	}
+	return &clone
+}
+
+// Count returns the number of values added
+func (s *DDSketch) Count() uint64 { return s.count }
+
+// Sum returns the exact sum of the values added
+func (s *DDSketch) Sum() float64 { return s.sum }
+
+// Min returns the smallest value added, 0 when empty
+func (s *DDSketch) Min() float64 {
+	if s.count == 0 {
+		return 0
+	}
+	return s.min
+}
+
+// Max returns the largest value added, 0 when empty
+func (s *DDSketch) Max() float64 {
+	if s.count == 0 {
+		return 0
+	}
+	return s.max
+}
+
+// Mean returns the exact mean of the values added
+func (s *DDSketch) Mean() float64 {
+	if s.count == 0 {
+		return 0
+	}
+	return s.sum / float64(s.count)
+}
+
+func (s *DDSketch) index(v float64) int {
+	return int(math.Ceil(math.Log(v) / s.logG))
+}
+
+// value is the midpoint of bucket i in relative terms
+func (s *DDSketch) value(i int) float64 {
+	return 2 * math.Pow(s.gamma, float64(i)) / (s.gamma + 1)
+}
+
+func (s *DDSketch) indexes() []int {
+	idx := make([]int, 0, len(s.bins))
+	for i := range s.bins {
+		idx = append(idx, i)
+	}
+	sort.Ints(idx)
+	return idx
+}
+
+// collapse folds the lowest buckets together once there are too many
+// keeping the accuracy of the high quantiles that matter for latency
+func (s *DDSketch) collapse() {
+	if len(s.bins) <= s.maxBins {
+		return
+	}
+
+	idx := s.indexes()
+	excess := len(idx) - s.maxBins
+	target := idx[excess]
+	for _, i := range idx[:excess] {
+		s.bins[target] += s.bins[i]
+		delete(s.bins, i)
+	}
+}
+
+// sketchJSON is the wire form used by MarshalJSON
+type sketchJSON struct {
+	Alpha float64        `json:"alpha"`
+	Zero  uint64         `json:"zero,omitempty"`
+	Count uint64         `json:"count"`
+	Sum   float64        `json:"sum"`
+	Min   float64        `json:"min"`
+	Max   float64        `json:"max"`
+	Bins  map[int]uint64 `json:"bins"`
+}
+
+func (s *DDSketch) MarshalJSON() ([]byte, error) {
+	return json.Marshal(sketchJSON{
+		Alpha: s.alpha,
+		Zero:  s.zero,
+		Count: s.count,
+		Sum:   s.sum,
+		Min:   s.Min(),
+		Max:   s.Max(),
+		Bins:  s.bins,
+	})
+}
+
+func (s *DDSketch) UnmarshalJSON(data []byte) error {
+	var w sketchJSON
+	if err := json.Unmarshal(data, &w); err != nil {
+		return err
+	}
+
+	*s = *New(w.Alpha)
+	s.zero, s.count, s.sum = w.Zero, w.Count, w.Sum
+	if w.Count > 0 {
+		s.min, s.max = w.Min, w.Max
+	}
+	for i, n := range w.Bins {
+		s.bins[i] = n
+	}
+	return nil
+}
+
+// MarshalBinary encodes the sketch compactly
+// the layout is alpha, zero, count, sum, min, max then delta encoded bins
+func (s *DDSketch) MarshalBinary() ([]byte, error) {
+	buf := make([]byte, 0, 48+len(s.bins)*4)
+	buf = binary.LittleEndian.AppendUint64(buf, math.Float64bits(s.alpha))
+	buf = binary.AppendUvarint(buf, s.zero)
+	buf = binary.AppendUvarint(buf, s.count)
+	buf = binary.LittleEndian.AppendUint64(buf, math.Float64bits(s.sum))
+	buf = binary.LittleEndian.AppendUint64(buf, math.Float64bits(s.Min()))
+	buf = binary.LittleEndian.AppendUint64(buf, math.Float64bits(s.Max()))
+	buf = binary.AppendUvarint(buf, uint64(len(s.bins)))
+
+	prev := 0
+	for _, i := range s.indexes() {
+		buf = binary.AppendVarint(buf, int64(i-prev))
+		buf = binary.AppendUvarint(buf, s.bins[i])
+		prev = i
+	}
+	return buf, nil
+}
+
+var errCorrupt = errors.New("corrupt sketch encoding")
+
+func (s *DDSketch) UnmarshalBinary(data []byte) error {
+	r := &reader{data: data}
+	alpha := r.float()
+	zero := r.uvarint()
+	count := r.uvarint()
+	sum, minV, maxV := r.float(), r.float(), r.float()
+	n := r.uvarint()
+	if r.err != nil || n > uint64(len(data)) {
+		return errCorrupt
+	}
+
+	*s = *New(alpha)
+	s.zero, s.count, s.sum = zero, count, sum
+	if count > 0 {
+		s.min, s.max = minV, maxV
+	}
+
+	prev := 0
+	for range n {
+		i := prev + int(r.varint())
+		s.bins[i] = r.uvarint()
+		prev = i
+	}
+	if r.err != nil {
+		return errCorrupt
+	}
+	return nil
+}
+
+// Encode returns the binary form as base64 so it fits a string field
+func (s *DDSketch) Encode() string {
+	data, _ := s.MarshalBinary()
+	return base64.StdEncoding.EncodeToString(data)
+}
+
+// Decode parses a sketch produced by Encode
+func Decode(encoded string) (*DDSketch, error) {
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, err
+	}
+	s := &DDSketch{}
+	if err := s.UnmarshalBinary(data); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// reader walks a binary encoding remembering the first error
+type reader struct {
+	data []byte
+	err  error
+}
+
+func (r *reader) float() float64 {
+	if r.err != nil || len(r.data) < 8 {
+		r.err = errCorrupt
+		return 0
+	}
+	v := math.Float64frombits(binary.LittleEndian.Uint64(r.data))
+	r.data = r.data[8:]
+	return v
+}
+
+func (r *reader) uvarint() uint64 {
+	if r.err != nil {
+		return 0
+	}
+	v, n := binary.Uvarint(r.data)
+	if n <= 0 {
+		r.err = errCorrupt
+		return 0
+	}
+	r.data = r.data[n:]
+	return v
+}
+
+func (r *reader) varint() int64 {
+	if r.err != nil {
+		return 0
+	}
+	v, n := binary.Varint(r.data)
+	if n <= 0 {
+		r.err = errCorrupt
+		return 0
+	}
+	r.data = r.data[n:]
+	return v
+}
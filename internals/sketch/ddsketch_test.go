@@ -0,0 +1,166 @@
+package sketch
+
+import (
+	"encoding/json"
+	"math"
+	"sort"
+	"testing"
+)
+
+var quantiles = []float64{0, 0.1, 0.5, 0.9, 0.99, 0.999, 1}
+
+// latencies returns n values spread over several orders of magnitude
+func latencies(n int) []float64 {
+	values := make([]float64, n)
+	for i := range values {
+		values[i] = math.Pow(10, 3+6*float64(i)/float64(n)) // 1µs to 1ms in nanoseconds
+	}
+	return values
+}
+
+func sketchOf(values []float64) *DDSketch {
+	s := New(DefaultRelativeAccuracy)
+	for _, v := range values {
+		s.Add(v)
+	}
+	return s
+}
+
+func assertSameSketch(t *testing.T, got, want *DDSketch) {
+	t.Helper()
+	if got.Count() != want.Count() || got.Sum() != want.Sum() || got.Min() != want.Min() || got.Max() != want.Max() {
+		t.Errorf("count, sum, min, max = %d, %g, %g, %g; want %d, %g, %g, %g",
+			got.Count(), got.Sum(), got.Min(), got.Max(), want.Count(), want.Sum(), want.Min(), want.Max())
+	}
+	for _, q := range quantiles {
+		if g, w := got.Quantile(q), want.Quantile(q); g != w {
+			t.Errorf("quantile %g = %g, want %g", q, g, w)
+		}
+	}
+}
+
+func TestDDSketchRelativeAccuracy(t *testing.T) {
+	values := latencies(100000)
+	s := sketchOf(values)
+	sort.Float64s(values)
+
+	for _, q := range quantiles {
+		exact := values[int(q*float64(len(values)-1))]
+		got := s.Quantile(q)
+		if math.Abs(got-exact) > DefaultRelativeAccuracy*exact*(1+1e-9) {
+			t.Errorf("quantile %g = %g, exact %g, off by more than %g", q, got, exact, DefaultRelativeAccuracy)
+		}
+	}
+}
+
+func TestDDSketchZeroAndEmpty(t *testing.T) {
+	s := New(DefaultRelativeAccuracy)
+	if s.Quantile(0.5) != 0 || s.Min() != 0 || s.Max() != 0 || s.Mean() != 0 {
+		t.Error("an empty sketch does not read as zero")
+	}
+
+	s.Add(0)
+	s.Add(-5) // counts as zero
+	s.Add(100)
+	if got := s.Quantile(0.5); got != 0 {
+		t.Errorf("median of 0, 0, 100 = %g, want 0", got)
+	}
+	if got := s.Count(); got != 3 {
+		t.Errorf("count = %d, want 3", got)
+	}
+}
+
+func TestDDSketchMerge(t *testing.T) {
+	values := latencies(10000)
+	whole := sketchOf(values)
+
+	merged := sketchOf(values[:3000])
+	if err := merged.Merge(sketchOf(values[3000:])); err != nil {
+		t.Fatal(err)
+	}
+	if err := merged.Merge(New(DefaultRelativeAccuracy)); err != nil {
+		t.Fatalf("merging an empty sketch: %v", err)
+	}
+
+	if merged.Count() != whole.Count() || merged.Min() != whole.Min() || merged.Max() != whole.Max() {
+		t.Errorf("merged count, min, max = %d, %g, %g; want %d, %g, %g",
+			merged.Count(), merged.Min(), merged.Max(), whole.Count(), whole.Min(), whole.Max())
+	}
+	if math.Abs(merged.Sum()-whole.Sum()) > 1e-9*whole.Sum() {
+		t.Errorf("merged sum = %g, want %g", merged.Sum(), whole.Sum())
+	}
+	for _, q := range quantiles {
+		if g, w := merged.Quantile(q), whole.Quantile(q); g != w {
+			t.Errorf("merged quantile %g = %g, want %g", q, g, w)
+		}
+	}
+
+	coarse := New(0.05)
+	coarse.Add(1000)
+	if err := merged.Merge(coarse); err == nil {
+		t.Error("merging sketches of different accuracy did not fail")
+	}
+}
+
+func TestDDSketchClone(t *testing.T) {
+	s := sketchOf(latencies(100))
+	clone := s.Clone()
+	s.Add(1e12)
+
+	if clone.Count() != 100 || clone.Max() == 1e12 {
+		t.Error("adding to the original changed the clone")
+	}
+	assertSameSketch(t, clone, sketchOf(latencies(100)))
+}
+
+func TestDDSketchEncodingRoundTrip(t *testing.T) {
+	sketches := map[string]*DDSketch{
+		"empty":     New(DefaultRelativeAccuracy),
+		"with zero": sketchOf([]float64{0, 0, 1500, 2e6}),
+		"latencies": sketchOf(latencies(5000)),
+	}
+
+	for name, s := range sketches {
+		t.Run(name+"/binary", func(t *testing.T) {
+			data, err := s.MarshalBinary()
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got DDSketch
+			if err := got.UnmarshalBinary(data); err != nil {
+				t.Fatal(err)
+			}
+			assertSameSketch(t, &got, s)
+		})
+
+		t.Run(name+"/base64", func(t *testing.T) {
+			got, err := Decode(s.Encode())
+			if err != nil {
+				t.Fatal(err)
+			}
+			assertSameSketch(t, got, s)
+		})
+
+		t.Run(name+"/json", func(t *testing.T) {
+			data, err := json.Marshal(s)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got DDSketch
+			if err := json.Unmarshal(data, &got); err != nil {
+				t.Fatal(err)
+			}
+			assertSameSketch(t, &got, s)
+		})
+	}
+}
+
+func TestDDSketchRejectsCorruptEncoding(t *testing.T) {
+	data, _ := sketchOf(latencies(100)).MarshalBinary()
+	for _, cut := range []int{0, 7, 20, len(data) - 1} {
+		var s DDSketch
+		if err := s.UnmarshalBinary(data[:cut]); err == nil {
+			t.Errorf("decoding the first %d of %d bytes did not fail", cut, len(data))
+		}
+	}
+}
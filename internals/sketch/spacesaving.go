@@ -0,0 +1,183 @@
+package sketch
+
+import (
+	"container/heap"
+	"math"
+	"sort"
+	"time"
+)
+
+// renormalizeAt bounds the forward decay exponent before counts are rescaled
+const renormalizeAt = 50.0
+
+// SpaceSaving keeps approximate counts of the most frequent keys in bounded memory
+// when full, a new key takes over the smallest counter and inherits its count as error
+// counts decay exponentially with the given half life, zero disables decay
+// it is not safe for concurrent use, reads rescale the counts as well as writes
+// see Metwally et al, Efficient computation of frequent and top-k elements in data streams
+type SpaceSaving struct {
+	capacity int
+	lambda   float64 // decay per second
+	landmark time.Time
+	items    map[string]*counter
+	heap     counterHeap
+}
+
+// Item is an estimated count, the true count lies in [Count-Error, Count]
+type Item struct {
+	Key   string
+	Count float64
+	Error float64
+}
+
+func NewSpaceSaving(capacity int, halfLife time.Duration) *SpaceSaving {
+	if capacity <= 0 {
+		capacity = 1000
+	}
+	var lambda float64
+	if halfLife > 0 {
+		lambda = math.Ln2 / halfLife.Seconds()
+	}
+	return &SpaceSaving{
+		capacity: capacity,
+		lambda:   lambda,
+		items:    make(map[string]*counter, capacity),
+	}
+}
+
+// Add counts key once at ts
+func (s *SpaceSaving) Add(key string, ts time.Time) {
+	s.AddWeight(key, 1, ts)
+}
+
+// AddWeight counts key with weight w at ts
+// timestamps may arrive out of order, older ones simply weigh less
+func (s *SpaceSaving) AddWeight(key string, w float64, ts time.Time) {
+	if w <= 0 {
+		return
+	}
+	s.advance(ts)
+	w *= s.scale(ts)
+
+	if c, ok := s.items[key]; ok {
+		c.count += w
+		heap.Fix(&s.heap, c.index)
+		return
+	}
+
+	if len(s.items) < s.capacity {
+		c := &counter{key: key, count: w}
+		s.items[key] = c
+		heap.Push(&s.heap, c)
+		return
+	}
+
+	min := s.heap[0]
+	delete(s.items, min.key)
+	min.key = key
+	min.err = min.count
+	min.count += w
+	s.items[key] = min
+	heap.Fix(&s.heap, 0)
+}
+
+// Estimate returns the decayed count of key as of now, 0 when it is not tracked
+func (s *SpaceSaving) Estimate(key string, now time.Time) Item {
+	c, ok := s.items[key]
+	if !ok {
+		return Item{Key: key}
+	}
+	s.advance(now)
+	f := 1 / s.scale(now)
+	return Item{Key: key, Count: c.count * f, Error: c.err * f}
+}
+
+// Top returns up to n keys with the highest decayed counts as of now
+// a negative n returns every key
+func (s *SpaceSaving) Top(n int, now time.Time) []Item {
+	s.advance(now)
+	counters := make([]*counter, len(s.heap))
+	copy(counters, s.heap)
+	sort.Slice(counters, func(i, j int) bool {
+		if counters[i].count != counters[j].count {
+			return counters[i].count > counters[j].count
+		}
+		return counters[i].key < counters[j].key
+	})
+	if n >= 0 && len(counters) > n {
+		counters = counters[:n]
+	}
+
+	f := 1 / s.scale(now)
+	items := make([]Item, len(counters))
+	for i, c := range counters {
+		items[i] = Item{Key: c.key, Count: c.count * f, Error: c.err * f}
+	}
+	return items
+}
+
+// Len returns the number of keys tracked
+func (s *SpaceSaving) Len() int {
+	return len(s.items)
+}
+
+// advance sets the landmark on first use and rescales once ts is far enough
+// past it, reads call it too so a sketch left idle never scales to infinity
+func (s *SpaceSaving) advance(ts time.Time) {
+	if s.landmark.IsZero() {
+		s.landmark = ts
+	}
+	if s.lambda > 0 && s.lambda*ts.Sub(s.landmark).Seconds() > renormalizeAt {
+		s.rescale(ts)
+	}
+}
+
+// scale is the forward decay factor of ts relative to the landmark
+func (s *SpaceSaving) scale(ts time.Time) float64 {
+	if s.lambda == 0 {
+		return 1
+	}
+	return math.Exp(s.lambda * ts.Sub(s.landmark).Seconds())
+}
+
+// rescale moves the landmark to ts, every count shrinks by the same
+// factor so the heap order holds
+func (s *SpaceSaving) rescale(ts time.Time) {
+	f := 1 / s.scale(ts)
+	for _, c := range s.heap {
+		c.count *= f
+		c.err *= f
+	}
+	s.landmark = ts
+}
+
+type counter struct {
+	key   string
+	count float64
+	err   float64
+	index int
+}
+
+// counterHeap is a min heap on count
+type counterHeap []*counter
+
+func (h counterHeap) Len() int           { return len(h) }
+func (h counterHeap) Less(i, j int) bool { return h[i].count < h[j].count }
+func (h counterHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+func (h *counterHeap) Push(x any) {
+	c := x.(*counter)
+	c.index = len(*h)
+	*h = append(*h, c)
+}
+
+func (h *counterHeap) Pop() any {
+	old := *h
+	c := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return c
+}
@@ -0,0 +1,129 @@
+package sketch
+
+import (
+	"fmt"
+	"math"
+	"testing"
+	"time"
+)
+
+var t0 = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func TestSpaceSavingKeepsHeavyHittersUnderEviction(t *testing.T) {
+	const capacity = 10
+	s := NewSpaceSaving(capacity, 0)
+
+	// two heavy keys hidden in a long tail of keys seen once, far more keys than counters
+	truth := map[string]float64{}
+	total := 0.0
+	add := func(key string) {
+		s.Add(key, t0)
+		truth[key]++
+		total++
+	}
+	for i := 0; i < 1000; i++ {
+		add(fmt.Sprintf("tail-%d", i))
+		if i%3 == 0 {
+			add("heavy-a")
+		}
+		if i%5 == 0 {
+			add("heavy-b")
+		}
+	}
+
+	if s.Len() != capacity {
+		t.Fatalf("tracks %d keys, want %d", s.Len(), capacity)
+	}
+
+	// every key counted more than total/capacity times is guaranteed to be tracked
+	top := s.Top(2, t0)
+	for i, key := range []string{"heavy-a", "heavy-b"} {
+		if truth[key] <= total/capacity {
+			t.Fatalf("%s is not a heavy hitter, the test stream is wrong", key)
+		}
+		if top[i].Key != key {
+			t.Errorf("top[%d] = %s, want %s", i, top[i].Key, key)
+		}
+	}
+
+	// the true count of every tracked key lies in [Count-Error, Count]
+	for _, item := range s.Top(-1, t0) {
+		if exact := truth[item.Key]; exact > item.Count || exact < item.Count-item.Error {
+			t.Errorf("%s counted %g with error %g, true count %g", item.Key, item.Count, item.Error, exact)
+		}
+	}
+}
+
+func TestSpaceSavingTopLimit(t *testing.T) {
+	s := NewSpaceSaving(10, 0)
+	for i, key := range []string{"a", "b", "c"} {
+		s.AddWeight(key, float64(3-i), t0)
+	}
+
+	for _, tt := range []struct{ n, want int }{{-1, 3}, {0, 0}, {2, 2}, {5, 3}} {
+		if got := len(s.Top(tt.n, t0)); got != tt.want {
+			t.Errorf("Top(%d) returned %d keys, want %d", tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestSpaceSavingDecay(t *testing.T) {
+	s := NewSpaceSaving(10, time.Minute)
+	s.AddWeight("a", 8, t0)
+
+	if got := s.Estimate("a", t0.Add(3*time.Minute)).Count; math.Abs(got-1) > 1e-9 {
+		t.Errorf("after three half lives = %g, want 1", got)
+	}
+	// an older timestamp weighs less than the same use now
+	s.Add("b", t0.Add(-time.Minute))
+	if got := s.Estimate("b", t0).Count; math.Abs(got-0.5) > 1e-9 {
+		t.Errorf("use one half life old = %g, want 0.5", got)
+	}
+}
+
+func TestSpaceSavingRescaleOnReadIsIdempotent(t *testing.T) {
+	halfLife := time.Second
+	read := NewSpaceSaving(10, halfLife)
+	fresh := NewSpaceSaving(10, halfLife)
+	for _, s := range []*SpaceSaving{read, fresh} {
+		s.AddWeight("a", 8, t0)
+		s.AddWeight("b", 4, t0)
+	}
+
+	// far enough past the landmark for a read to rescale
+	later := t0.Add(100 * time.Second)
+	first := read.Top(-1, later)
+	again := read.Top(-1, later)
+	estimate := read.Estimate("a", later)
+	if len(first) != 2 || first[0] != again[0] || first[1] != again[1] || estimate != first[0] {
+		t.Fatalf("repeated reads differ: %+v, %+v, %+v", first, again, estimate)
+	}
+
+	// a sketch read only once agrees with one rescaled by earlier reads
+	last := t0.Add(101 * time.Second)
+	for _, key := range []string{"a", "b"} {
+		got, want := read.Estimate(key, last).Count, fresh.Estimate(key, last).Count
+		if math.Abs(got-want) > 1e-9*want {
+			t.Errorf("%s after a rescale = %g, without = %g", key, got, want)
+		}
+	}
+}
+
+func TestSpaceSavingLongIdle(t *testing.T) {
+	s := NewSpaceSaving(10, time.Second)
+	s.AddWeight("old", 1000, t0)
+
+	// decades of half lives, the scale alone would overflow
+	idle := t0.Add(time.Hour)
+	for _, item := range s.Top(-1, idle) {
+		if math.IsNaN(item.Count) || math.IsInf(item.Count, 0) || item.Count > 1e-300 {
+			t.Errorf("%s after a long idle = %g, want about 0", item.Key, item.Count)
+		}
+	}
+
+	s.Add("new", idle)
+	top := s.Top(1, idle)
+	if len(top) != 1 || top[0].Key != "new" || math.Abs(top[0].Count-1) > 1e-9 {
+		t.Errorf("top after a long idle = %+v, want new counted once", top)
+	}
+}
@@ -0,0 +1,516 @@
+package storage
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	influxhttp "github.com/influxdata/influxdb-client-go/v2/api/http"
+)
+
+// RecordWriter writes line protocol records
+// influxdb2 api.WriteAPIBlocking satisfies it
+type RecordWriter interface {
+	WriteRecord(ctx context.Context, line ...string) error
+}
+
+type BufferConfig struct {
+	BatchSize      int           // points per write
+	FlushInterval  time.Duration // longest time a point waits in memory
+	QueueSize      int           // points accepted before new ones go straight to the spool
+	MaxRetries     int           // attempts per batch before it is dropped, only without a spool
+	RetryBaseDelay time.Duration // first wait before writing again after a failure, doubled on every failure
+	RetryMaxDelay  time.Duration
+	WriteTimeout   time.Duration
+	SpoolPath      string // write-ahead file for unwritten points, empty disables spooling
+}
+
+func DefaultBufferConfig() BufferConfig {
+	return BufferConfig{
+		BatchSize:      500,
+		FlushInterval:  time.Second,
+		QueueSize:      10000,
+		MaxRetries:     5,
+		RetryBaseDelay: 200 * time.Millisecond,
+		RetryMaxDelay:  10 * time.Second,
+		WriteTimeout:   10 * time.Second,
+	}
+}
+
+// WriteStats counts points as they move through the buffer
+type WriteStats struct {
+	Queued  uint64
+	Written uint64
+	Retried uint64
+	Spooled uint64
+	Dropped uint64
+}
+
+// BufferedWriter batches points in memory and writes them in the background
+// a batch that cannot be written is spooled to disk right away and the spool is
+// replayed on every flush tick once the backoff after the failure has passed,
+// so the queue keeps draining during an outage
+// without a spool the batch is kept in memory and retried on the same schedule
+type BufferedWriter struct {
+	writer RecordWriter
+	cfg    BufferConfig
+	queue  chan string
+	flush  chan chan struct{}
+	stop   chan struct{}
+	wg     sync.WaitGroup
+	spool  *spool
+
+	// backoff after a failed write, only touched by run
+	retryAt  time.Time
+	delay    time.Duration
+	attempts int // failed attempts in a row
+	lastErr  error
+
+	queued, written, retried, spooled, dropped atomic.Uint64
+}
+
+// NewBufferedWriter starts the background writer, zero config fields take their defaults
+func NewBufferedWriter(writer RecordWriter, cfg BufferConfig) *BufferedWriter {
+	def := DefaultBufferConfig()
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = def.BatchSize
+	}
+	if cfg.FlushInterval <= 0 {
+		cfg.FlushInterval = def.FlushInterval
+	}
+	if cfg.QueueSize <= 0 {
+		cfg.QueueSize = def.QueueSize
+	}
+	if cfg.MaxRetries <= 0 {
+		cfg.MaxRetries = def.MaxRetries
+	}
+	if cfg.RetryBaseDelay <= 0 {
+		cfg.RetryBaseDelay = def.RetryBaseDelay
+	}
+	if cfg.RetryMaxDelay <= 0 {
+		cfg.RetryMaxDelay = def.RetryMaxDelay
+	}
+	if cfg.WriteTimeout <= 0 {
+		cfg.WriteTimeout = def.WriteTimeout
+	}
+
+	bw := &BufferedWriter{
+		writer: writer,
+		cfg:    cfg,
+		queue:  make(chan string, cfg.QueueSize),
+		flush:  make(chan chan struct{}),
+		stop:   make(chan struct{}),
+	}
+
+	if cfg.SpoolPath != "" {
+		bw.spool = &spool{path: cfg.SpoolPath}
+	}
+
+	bw.wg.Add(1)
+	go bw.run()
+	return bw
+}
+
+// Write queues line protocol records without blocking
+// when the queue is full records go to the spool, or are dropped without one
+func (bw *BufferedWriter) Write(lines ...string) {
+	for _, line := range lines {
+		select {
+		case bw.queue <- line:
+			bw.queued.Add(1)
+		default:
+			bw.spoolOrDrop([]string{line}, errors.New("write queue full"))
+		}
+	}
+}
+
+// Flush writes everything queued so far and waits for it
+func (bw *BufferedWriter) Flush(ctx context.Context) error {
+	done := make(chan struct{})
+	select {
+	case bw.flush <- done:
+	case <-bw.stop:
+		return errors.New("buffered writer closed")
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Close flushes the queue, spooling what cannot be written, and stops the writer
+func (bw *BufferedWriter) Close() error {
+	select {
+	case <-bw.stop:
+		return nil
+	default:
+	}
+
+	close(bw.stop)
+	bw.wg.Wait()
+
+	s := bw.Stats()
+	log.Printf("buffered writer closed: queued=%d written=%d retried=%d spooled=%d dropped=%d",
+		s.Queued, s.Written, s.Retried, s.Spooled, s.Dropped)
+	return nil
+}
+
+// Stats returns a snapshot of the point counters
+func (bw *BufferedWriter) Stats() WriteStats {
+	return WriteStats{
+		Queued:  bw.queued.Load(),
+		Written: bw.written.Load(),
+		Retried: bw.retried.Load(),
+		Spooled: bw.spooled.Load(),
+		Dropped: bw.dropped.Load(),
+	}
+}
+
+// run collects batches and writes them until the writer is closed
+func (bw *BufferedWriter) run() {
+	defer bw.wg.Done()
+
+	ticker := time.NewTicker(bw.cfg.FlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]string, 0, bw.cfg.BatchSize)
+	bw.replaySpool() // points left over from the last run
+
+	for {
+		select {
+		case line := <-bw.queue:
+			batch = append(batch, line)
+			if len(batch) >= bw.cfg.BatchSize {
+				batch = bw.writeBatch(batch)
+			}
+
+		case <-ticker.C:
+			batch = bw.writeBatch(batch)
+			bw.replaySpool()
+
+		case done := <-bw.flush:
+			batch = bw.drain(batch)
+			batch = bw.writeBatch(batch)
+			bw.replaySpool()
+			close(done)
+
+		case <-bw.stop:
+			batch = bw.drain(batch)
+			for len(batch) > 0 {
+				n := min(len(batch), bw.cfg.BatchSize)
+				if err := bw.writeOnce(batch[:n]); err != nil {
+					bw.spoolOrDrop(batch, err)
+					return
+				}
+				batch = batch[n:]
+			}
+			return
+		}
+	}
+}
+
+// drain moves everything currently queued into the batch
+func (bw *BufferedWriter) drain(batch []string) []string {
+	for {
+		select {
+		case line := <-bw.queue:
+			batch = append(batch, line)
+		default:
+			return batch
+		}
+	}
+}
+
+// writeBatch writes the batch in chunks and returns what is left of it
+// a transient failure spools the rest at once, or keeps it for the next
+// attempt without a spool, and starts a backoff during which nothing is written
+func (bw *BufferedWriter) writeBatch(batch []string) []string {
+	if len(batch) == 0 {
+		return batch
+	}
+	if bw.backingOff() {
+		return bw.hold(batch, bw.lastErr)
+	}
+
+	if bw.attempts > 0 {
+		bw.retried.Add(uint64(len(batch)))
+	}
+	for start := 0; start < len(batch); start += bw.cfg.BatchSize {
+		chunk := batch[start:min(start+bw.cfg.BatchSize, len(batch))]
+		err := bw.writeOnce(chunk)
+		if err == nil {
+			continue
+		}
+		if !isTransient(err) {
+			bw.dropped.Add(uint64(len(chunk)))
+			log.Printf("buffered writer: dropping %d points rejected by InfluxDB: %v", len(chunk), err)
+			continue
+		}
+
+		bw.failed(err)
+		rest := batch[:copy(batch, batch[start:])]
+		if bw.spool == nil && bw.attempts >= bw.cfg.MaxRetries {
+			bw.dropped.Add(uint64(len(rest)))
+			log.Printf("buffered writer: dropping %d points after %d attempts: %v", len(rest), bw.attempts, err)
+			return batch[:0]
+		}
+		return bw.hold(rest, err)
+	}
+
+	bw.recovered()
+	return batch[:0]
+}
+
+// hold keeps points that cannot be written now, in the spool when there is one
+// or in memory up to QueueSize, beyond which the oldest are dropped
+func (bw *BufferedWriter) hold(batch []string, cause error) []string {
+	if bw.spool != nil {
+		bw.spoolOrDrop(batch, cause)
+		return batch[:0]
+	}
+	if excess := len(batch) - bw.cfg.QueueSize; excess > 0 {
+		bw.dropped.Add(uint64(excess))
+		log.Printf("buffered writer: dropping %d points: %v", excess, cause)
+		batch = batch[:copy(batch, batch[excess:])]
+	}
+	return batch
+}
+
+// backingOff reports whether a recent failure still holds writes back
+func (bw *BufferedWriter) backingOff() bool {
+	return bw.attempts > 0 && time.Now().Before(bw.retryAt)
+}
+
+// failed starts or doubles the backoff after a transient failure
+func (bw *BufferedWriter) failed(err error) {
+	if bw.attempts == 0 {
+		bw.delay = bw.cfg.RetryBaseDelay
+	} else {
+		bw.delay = min(bw.delay*2, bw.cfg.RetryMaxDelay)
+	}
+	bw.attempts++
+	bw.retryAt = time.Now().Add(bw.delay)
+	bw.lastErr = err
+}
+
+// recovered ends the backoff once a write went through
+func (bw *BufferedWriter) recovered() {
+	if bw.attempts > 0 {
+		log.Printf("buffered writer: writes recovered after %d failed attempts", bw.attempts)
+	}
+	bw.attempts = 0
+	bw.lastErr = nil
+}
+
+// writeOnce sends a single request
+func (bw *BufferedWriter) writeOnce(lines []string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), bw.cfg.WriteTimeout)
+	defer cancel()
+
+	if err := bw.writer.WriteRecord(ctx, lines...); err != nil {
+		return err
+	}
+	bw.written.Add(uint64(len(lines)))
+	return nil
+}
+
+// spoolOrDrop saves lines to the spool, counting them as dropped
+// when there is no spool or it cannot be written
+func (bw *BufferedWriter) spoolOrDrop(lines []string, cause error) {
+	if bw.spool == nil {
+		bw.dropped.Add(uint64(len(lines)))
+		log.Printf("buffered writer: dropping %d points: %v", len(lines), cause)
+		return
+	}
+
+	if err := bw.spool.append(lines); err != nil {
+		bw.dropped.Add(uint64(len(lines)))
+		log.Printf("buffered writer: dropping %d points, spool failed: %v (after %v)", len(lines), err, cause)
+		return
+	}
+
+	bw.spooled.Add(uint64(len(lines)))
+	log.Printf("buffered writer: spooled %d points: %v", len(lines), cause)
+}
+
+// replaySpool writes spooled points back in BatchSize chunks read from the file
+// the spool is rotated first so points spooled while the replay runs land in
+// a fresh file, a transient failure puts what is left back and starts a backoff
+func (bw *BufferedWriter) replaySpool() {
+	if bw.spool == nil || bw.backingOff() {
+		return
+	}
+
+	file, err := bw.spool.take()
+	if err != nil {
+		log.Printf("buffered writer: failed to open spool: %v", err)
+		return
+	}
+	if file == nil {
+		return
+	}
+	defer file.Close()
+
+	var replayed, done, read int64 // points written, bytes fully handled, bytes read
+	chunk := make([]string, 0, bw.cfg.BatchSize)
+	write := func() bool {
+		if len(chunk) == 0 {
+			return true
+		}
+		err := bw.writeOnce(chunk)
+		switch {
+		case err == nil:
+			replayed += int64(len(chunk))
+		case !isTransient(err):
+			bw.dropped.Add(uint64(len(chunk)))
+			log.Printf("buffered writer: dropping %d spooled points rejected by InfluxDB: %v", len(chunk), err)
+		default:
+			bw.failed(err)
+			return false
+		}
+		chunk = chunk[:0]
+		done = read
+		return true
+	}
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	ok := true
+	for ok && scanner.Scan() {
+		read += int64(len(scanner.Bytes())) + 1
+		if line := strings.TrimSpace(scanner.Text()); line != "" {
+			chunk = append(chunk, line)
+		}
+		if len(chunk) >= bw.cfg.BatchSize {
+			ok = write()
+		}
+	}
+	if ok {
+		if err := scanner.Err(); err != nil {
+			log.Printf("buffered writer: failed to read spool: %v", err)
+			ok = false
+		} else {
+			ok = write()
+		}
+	}
+
+	if done == 0 && !ok {
+		return // the taken file stays and is replayed next time
+	}
+	if err := bw.spool.finish(file, done, ok); err != nil {
+		log.Printf("buffered writer: failed to rewrite spool: %v", err)
+		return
+	}
+	if ok {
+		bw.recovered()
+	}
+	log.Printf("buffered writer: replayed %d spooled points", replayed)
+}
+
+// isTransient reports whether a failed write is worth retrying
+// network errors, throttling and server errors are, rejected data is not
+func isTransient(err error) bool {
+	var httpErr *influxhttp.Error
+	if !errors.As(err, &httpErr) {
+		return true
+	}
+	code := httpErr.StatusCode
+	return code == 0 || code == 429 || code >= 500
+}
+
+// spool is an append only file of line protocol records
+type spool struct {
+	mu   sync.Mutex
+	path string
+}
+
+func (s *spool) append(lines []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	file, err := os.OpenFile(s.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+
+	if _, err := file.WriteString(strings.Join(lines, "\n") + "\n"); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// take moves the spool aside and opens it for a replay, nil when there is nothing to replay
+// a file left aside by an unfinished replay is returned again first
+func (s *spool) take() (*os.File, error) {
+	s.mu.Lock()
+	_, err := os.Stat(s.replayPath())
+	if os.IsNotExist(err) {
+		err = os.Rename(s.path, s.replayPath())
+	}
+	s.mu.Unlock()
+
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return os.Open(s.replayPath())
+}
+
+// finish removes the taken file once a replay handled it up to offset
+// an unfinished replay first appends the rest of the file to the spool
+// a crash in between only replays points twice, which InfluxDB absorbs
+// as the same series and timestamp
+func (s *spool) finish(taken *os.File, offset int64, complete bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !complete {
+		if _, err := taken.Seek(offset, io.SeekStart); err != nil {
+			return err
+		}
+		file, err := os.OpenFile(s.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			return fmt.Errorf("failed to write spool: %w", err)
+		}
+		if _, err := io.Copy(file, taken); err != nil {
+			file.Close()
+			return fmt.Errorf("failed to write spool: %w", err)
+		}
+		if err := file.Sync(); err != nil {
+			file.Close()
+			return err
+		}
+		if err := file.Close(); err != nil {
+			return err
+		}
+	}
+
+	if err := os.Remove(s.replayPath()); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+func (s *spool) replayPath() string {
+	return s.path + ".replaying"
+}
@@ -0,0 +1,204 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fakeWriter records written points and fails while down is set
+type fakeWriter struct {
+	down atomic.Bool
+
+	mu     sync.Mutex
+	seen   map[string]int
+	calls  int
+	widest int
+}
+
+func newFakeWriter() *fakeWriter {
+	return &fakeWriter{seen: make(map[string]int)}
+}
+
+func (f *fakeWriter) WriteRecord(ctx context.Context, lines ...string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.calls++
+	if f.down.Load() {
+		return errors.New("connection refused")
+	}
+	f.widest = max(f.widest, len(lines))
+	for _, line := range lines {
+		f.seen[line]++
+	}
+	return nil
+}
+
+func (f *fakeWriter) missing(lines []string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	n := 0
+	for _, line := range lines {
+		if f.seen[line] == 0 {
+			n++
+		}
+	}
+	return n
+}
+
+func points(from, to int) []string {
+	lines := make([]string, 0, to-from)
+	for i := from; i < to; i++ {
+		lines = append(lines, fmt.Sprintf("m v=%d", i))
+	}
+	return lines
+}
+
+func flush(t *testing.T, bw *BufferedWriter) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := bw.Flush(ctx); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+}
+
+func TestBufferedWriterSpoolsDuringOutage(t *testing.T) {
+	w := newFakeWriter()
+	w.down.Store(true)
+	path := filepath.Join(t.TempDir(), "spool.lp")
+
+	bw := NewBufferedWriter(w, BufferConfig{
+		BatchSize:      10,
+		FlushInterval:  10 * time.Millisecond,
+		QueueSize:      20,
+		RetryBaseDelay: time.Hour, // nothing is written again while the test runs
+		RetryMaxDelay:  time.Hour,
+		SpoolPath:      path,
+	})
+	defer bw.Close()
+
+	// flushing must not wait out the backoff and the queue keeps draining
+	start := time.Now()
+	for i := range 10 {
+		bw.Write(points(i*10, i*10+10)...)
+		flush(t, bw)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Fatalf("writes during the outage took %v", elapsed)
+	}
+
+	s := bw.Stats()
+	if s.Spooled != 100 || s.Written != 0 || s.Dropped != 0 {
+		t.Fatalf("stats = %+v, want all 100 points spooled", s)
+	}
+	w.mu.Lock()
+	calls := w.calls
+	w.mu.Unlock()
+	if calls != 1 {
+		t.Fatalf("writer called %d times during the backoff, want 1", calls)
+	}
+}
+
+func TestBufferedWriterReplaysAfterRecovery(t *testing.T) {
+	w := newFakeWriter()
+	w.down.Store(true)
+	path := filepath.Join(t.TempDir(), "spool.lp")
+
+	bw := NewBufferedWriter(w, BufferConfig{
+		BatchSize:      10,
+		FlushInterval:  5 * time.Millisecond,
+		QueueSize:      100,
+		RetryBaseDelay: 5 * time.Millisecond,
+		RetryMaxDelay:  20 * time.Millisecond,
+		SpoolPath:      path,
+	})
+	defer bw.Close()
+
+	lines := points(0, 200)
+	bw.Write(lines[:100]...)
+	flush(t, bw)
+	w.down.Store(false)
+	bw.Write(lines[100:]...)
+
+	deadline := time.Now().Add(2 * time.Second)
+	for w.missing(lines) > 0 {
+		if time.Now().After(deadline) {
+			t.Fatalf("%d points still missing after recovery, stats = %+v", w.missing(lines), bw.Stats())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if _, err := os.Stat(path + ".replaying"); !os.IsNotExist(err) {
+		t.Fatalf("replay file left behind: %v", err)
+	}
+	if data, err := os.ReadFile(path); err == nil && len(data) > 0 {
+		t.Fatalf("spool not emptied, %d bytes left", len(data))
+	}
+}
+
+func TestBufferedWriterStreamsSpoolInBatches(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "spool.lp")
+	lines := points(0, 5000)
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	w := newFakeWriter()
+	bw := NewBufferedWriter(w, BufferConfig{
+		BatchSize:     50,
+		FlushInterval: time.Hour,
+		SpoolPath:     path,
+	})
+	flush(t, bw) // the spool left by a previous run is replayed on start
+	bw.Close()
+
+	if n := w.missing(lines); n != 0 {
+		t.Fatalf("%d spooled points not replayed", n)
+	}
+	if w.widest > 50 {
+		t.Fatalf("replay wrote %d points at once, want at most BatchSize", w.widest)
+	}
+}
+
+func TestSpoolFinishKeepsUnreplayedTail(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "spool.lp")
+	s := &spool{path: path}
+	if err := s.append([]string{"a", "b", "c"}); err != nil {
+		t.Fatal(err)
+	}
+
+	file, err := s.take()
+	if err != nil || file == nil {
+		t.Fatalf("take = %v, %v", file, err)
+	}
+	defer file.Close()
+
+	// a point spooled while the replay runs goes to a fresh file
+	if err := s.append([]string{"d"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.finish(file, int64(len("a\n")), false); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(data), "d\nb\nc\n"; got != want {
+		t.Fatalf("spool = %q, want %q", got, want)
+	}
+	if _, err := os.Stat(s.replayPath()); !os.IsNotExist(err) {
+		t.Fatalf("replay file left behind: %v", err)
+	}
+}
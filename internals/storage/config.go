@@ -1,8 +1,8 @@
 package storage
 
 import (
-	"log"
 	"os"
+	"strings"
 )
 
 type InfluxConfig struct {
@@ -12,22 +12,63 @@ type InfluxConfig struct {
 	Bucket string
 }
 
-func LoadInfluxConfig() *InfluxConfig {
-	url := os.Getenv("INFLUXDB_URL")
-	token := os.Getenv("INFLUXDB_TOKEN")
-	org := os.Getenv("INFLUXDB_ORG")
-	bucket := os.Getenv("INFLUXDB_BUCKET")
+// MissingSettingsError lists the InfluxDB settings that were left empty
+// by the environment variable that sets them
+type MissingSettingsError struct {
+	Settings []string
+}
+
+func (e *MissingSettingsError) Error() string {
+	return "InfluxDB settings are not set: " + strings.Join(e.Settings, ", ")
+}
 
-	if url == "" || token == "" || org == "" || bucket == "" {
-		log.Fatal("InfluxDB env variables are not set")
+// Validate reports every empty setting in a single MissingSettingsError
+func (c *InfluxConfig) Validate() error {
+	var missing []string
+	for _, setting := range []struct{ name, value string }{
+		{"INFLUXDB_URL", c.URL},
+		{"INFLUXDB_TOKEN", c.Token},
+		{"INFLUXDB_ORG", c.Org},
+		{"INFLUXDB_BUCKET", c.Bucket},
+	} {
+		if setting.value == "" {
+			missing = append(missing, setting.name)
+		}
 	}
 
+	if len(missing) > 0 {
+		return &MissingSettingsError{Settings: missing}
+	}
+	return nil
+}
+
+// LoadInfluxConfig reads the connection settings from env
+func LoadInfluxConfig() (*InfluxConfig, error) {
 	cfg := &InfluxConfig{
-		URL:    url,
-		Token:  token,
-		Org:    org,
-		Bucket: bucket,
+		URL:    os.Getenv("INFLUXDB_URL"),
+		Token:  os.Getenv("INFLUXDB_TOKEN"),
+		Org:    os.Getenv("INFLUXDB_ORG"),
+		Bucket: os.Getenv("INFLUXDB_BUCKET"),
 	}
 
-	return cfg
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// Metrics sink names accepted in METRICS_SINKS
+const (
+	SinkInflux = "influx"
+	SinkFile   = "file"
+	SinkMemory = "memory"
+)
+
+// SinkConfig selects the metrics sinks and carries their settings
+type SinkConfig struct {
+	Sinks    []string
+	FilePath string
+	Influx   InfluxConfig
+	Buffer   BufferConfig
+	Limits   SeriesLimits
 }
@@ -3,24 +3,44 @@ package storage
 import (
 	"context"
 	"fmt"
-	"log"
 	"strings"
 	"time"
 
 	"github.com/Udehlee/tweet-stream/models"
 	"github.com/Udehlee/tweet-stream/utils"
 	influxdb2 "github.com/influxdata/influxdb-client-go/v2"
+	"github.com/influxdata/influxdb-client-go/v2/api/write"
 )
 
 type InfluxWriter struct {
 	Client influxdb2.Client
 	Org    string
 	Bucket string
+	Buffer *BufferedWriter
+	Limits SeriesLimits
+
+	hashtagSeries *seriesBudget
+	userSeries    *seriesBudget
+}
+
+func NewInfluxWriter(client influxdb2.Client, org, bucket string, buffer BufferConfig, limits SeriesLimits) *InfluxWriter {
+	return &InfluxWriter{
+		Client:        client,
+		Org:           org,
+		Bucket:        bucket,
+		Buffer:        NewBufferedWriter(client.WriteAPIBlocking(org, bucket), buffer),
+		Limits:        limits,
+		hashtagSeries: newSeriesBudget(limits.MaxHashtagSeries),
+		userSeries:    newSeriesBudget(limits.MaxUserSeries),
+	}
 }
 
-// ConnectToInfluxDB loads the config from env and connects to InfluxDB
-func ConnectToInfluxDB() (*InfluxWriter, error) {
-	cfg := LoadInfluxConfig()
+// ConnectToInfluxDB connects to InfluxDB and starts the buffered writer
+func ConnectToInfluxDB(cfg InfluxConfig, buffer BufferConfig, limits SeriesLimits) (*InfluxWriter, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
 	client := influxdb2.NewClient(cfg.URL, cfg.Token)
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
@@ -37,50 +57,248 @@ func ConnectToInfluxDB() (*InfluxWriter, error) {
 
 	fmt.Printf("InfluxDB connection established. Status: %s\n", health.Status)
 
-	influx := &InfluxWriter{
-		Client: client,
-		Org:    cfg.Org,
-		Bucket: cfg.Bucket,
-	}
-
-	return influx, nil
+	return NewInfluxWriter(client, cfg.Org, cfg.Bucket, buffer, limits), nil
 }
 
-// Close closes the InfluxDB client
-func (iw *InfluxWriter) Close() {
+// Close flushes buffered points, spooling what cannot be written, then closes the client
+func (iw *InfluxWriter) Close() error {
+	if iw.Buffer != nil {
+		iw.Buffer.Close()
+	}
 	iw.Client.Close()
 	fmt.Println("InfluxDB client closed.")
+	return nil
 }
 
-// Insert saves WindowMetrics point to InfluxDB
+// Insert queues the points of a window for the buffered writer
+// the window summary goes to tweet_metrics, hashtags, users and the
+// verification split get their own measurements so each can be charted as a series
+// session windows only write a user_sessions point
 func (iw *InfluxWriter) Insert(metrics models.WindowMetrics) error {
-	writeAPI := iw.Client.WriteAPIBlocking(iw.Org, iw.Bucket)
+	var points []*write.Point
+	if metrics.Key != "" {
+		points = append(points, iw.sessionPoint(metrics))
+	} else {
+		points = append(points, iw.windowPoint(metrics), iw.verificationPoint(metrics))
+		points = append(points, iw.hashtagPoints(metrics)...)
+		points = append(points, iw.userPoints(metrics)...)
+		points = append(points, iw.anomalyPoints(metrics)...)
+		points = append(points, iw.trendingPoints(metrics)...)
+	}
+
+	lines := make([]string, len(points))
+	for i, point := range points {
+		lines[i] = strings.TrimSuffix(write.PointToLineProtocol(point, time.Nanosecond), "\n")
+	}
+	iw.Buffer.Write(lines...)
+	return nil
+}
+
+// windowPoint is the tweet_metrics summary of the window
+func (iw *InfluxWriter) windowPoint(metrics models.WindowMetrics) *write.Point {
 	hashtags := utils.ExtractHashtags(metrics.TrendingHashtags)
 
-	point := influxdb2.NewPoint(
+	fields := map[string]interface{}{
+		"total_events":      metrics.TotalEvents,
+		"total_tweets":      metrics.TotalTweets,
+		"updated_tweets":    metrics.UpdatedTweets,
+		"deleted_tweets":    metrics.DeletedTweets,
+		"total_engagement":  metrics.TotalEngagement,
+		"min_latency_ms":    metrics.MinLatency.Milliseconds(),
+		"max_latency_ms":    metrics.MaxLatency.Milliseconds(),
+		"avg_latency_ms":    metrics.AvgLatency.Milliseconds(),
+		"p50_latency_ms":    durationMillis(metrics.P50Latency),
+		"p90_latency_ms":    durationMillis(metrics.P90Latency),
+		"p99_latency_ms":    durationMillis(metrics.P99Latency),
+		"p999_latency_ms":   durationMillis(metrics.P999Latency),
+		"revision":          metrics.Revision,
+		"late_events":       metrics.LateEvents,
+		"late_dropped":      metrics.LateDropped,
+		"is_anomaly":        metrics.IsAnomaly,
+		"anomaly_count":     len(metrics.Anomalies),
+		"trending_hashtags": strings.Join(hashtags, ","),
+	}
+	if metrics.LatencySketch != nil {
+		// merge with sketch.Decode to roll windows up without the raw events
+		fields["latency_sketch"] = metrics.LatencySketch.Encode()
+	}
+
+	return influxdb2.NewPoint(
 		"tweet_metrics",
 		map[string]string{
 			"source": "tweet_stream",
+			"window": metrics.Window,
+		},
+		fields,
+		metrics.WindowEnd,
+	)
+}
+
+// trendingPoints writes one trending_hashtags point per trending tag, tags
+// past the series budget are skipped since their rates cannot be summed
+func (iw *InfluxWriter) trendingPoints(metrics models.WindowMetrics) []*write.Point {
+	points := make([]*write.Point, 0, len(metrics.TrendingNow))
+	for i, h := range metrics.TrendingNow {
+		if !iw.hashtagSeries.admit(h.Tag) {
+			continue
+		}
+		points = append(points, influxdb2.NewPoint(
+			"trending_hashtags",
+			map[string]string{
+				"window":  metrics.Window,
+				"hashtag": h.Tag,
+			},
+			map[string]interface{}{
+				"rank":     i + 1,
+				"count":    h.Count,
+				"rate":     h.Rate,
+				"baseline": h.Baseline,
+				"velocity": h.Velocity,
+			},
+			metrics.WindowEnd,
+		))
+	}
+	return points
+}
+
+// anomalyPoints writes one anomalies point per anomalous metric of the window
+func (iw *InfluxWriter) anomalyPoints(metrics models.WindowMetrics) []*write.Point {
+	points := make([]*write.Point, 0, len(metrics.Anomalies))
+	for _, a := range metrics.Anomalies {
+		points = append(points, influxdb2.NewPoint(
+			"anomalies",
+			map[string]string{
+				"window":    metrics.Window,
+				"metric":    a.Metric,
+				"method":    a.Method,
+				"direction": a.Direction,
+				"severity":  a.Severity,
+			},
+			map[string]interface{}{
+				"value":    a.Value,
+				"expected": a.Expected,
+				"score":    a.Score,
+			},
+			metrics.WindowEnd,
+		))
+	}
+	return points
+}
+
+// durationMillis keeps sub millisecond precision for percentiles
+func durationMillis(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+// sessionPoint summarises one user session
+// users past the series budget share the _other series
+func (iw *InfluxWriter) sessionPoint(metrics models.WindowMetrics) *write.Point {
+	user := metrics.Key
+	if !iw.userSeries.admit(user) {
+		user = OtherSeries
+	}
+
+	return influxdb2.NewPoint(
+		"user_sessions",
+		map[string]string{
+			"window":  metrics.Window,
+			"user_id": user,
+		},
+		map[string]interface{}{
+			"events":      metrics.TotalEvents,
+			"tweets":      metrics.TotalTweets,
+			"engagement":  metrics.TotalEngagement,
+			"duration_ms": metrics.WindowEnd.Sub(metrics.WindowStart).Milliseconds(),
+			"revision":    metrics.Revision,
+		},
+		metrics.WindowEnd,
+	)
+}
+
+// verificationPoint splits the window's tweets by author verification
+func (iw *InfluxWriter) verificationPoint(metrics models.WindowMetrics) *write.Point {
+	return influxdb2.NewPoint(
+		"verification_split",
+		map[string]string{
+			"source": "tweet_stream",
+			"window": metrics.Window,
 		},
 		map[string]interface{}{
-			"total_tweets":      metrics.TotalTweets,
-			"total_engagement":  metrics.TotalEngagement,
-			"min_latency_ms":    metrics.MinLatency.Milliseconds(),
-			"max_latency_ms":    metrics.MaxLatency.Milliseconds(),
-			"avg_latency_ms":    metrics.AvgLatency.Milliseconds(),
-			"is_anomaly":        metrics.IsAnomaly,
-			"trending_hashtags": strings.Join(hashtags, ","),
+			"verified":   metrics.VerifiedCount,
+			"unverified": metrics.UnverifiedCount,
 		},
 		metrics.WindowEnd,
 	)
+}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
+// hashtagPoints writes one hashtag_counts point per tag
+// tags past the per window limit or the series budget are summed into _other
+func (iw *InfluxWriter) hashtagPoints(metrics models.WindowMetrics) []*write.Point {
+	var points []*write.Point
+	other := 0
 
-	if err := writeAPI.WritePoint(ctx, point); err != nil {
-		log.Println("error writing to InfluxDB:", err)
-		return err
+	for i, h := range metrics.HashtagCounts {
+		if i >= iw.Limits.HashtagsPerWindow || !iw.hashtagSeries.admit(h.Tag) {
+			other += h.Count
+			continue
+		}
+		points = append(points, influxdb2.NewPoint(
+			"hashtag_counts",
+			map[string]string{"window": metrics.Window, "hashtag": h.Tag},
+			map[string]interface{}{"count": h.Count},
+			metrics.WindowEnd,
+		))
 	}
 
-	return nil
+	if other > 0 {
+		points = append(points, influxdb2.NewPoint(
+			"hashtag_counts",
+			map[string]string{"window": metrics.Window, "hashtag": OtherSeries},
+			map[string]interface{}{"count": other},
+			metrics.WindowEnd,
+		))
+	}
+	return points
+}
+
+// userPoints writes one user_activity point per active user
+// users past the per window limit or the series budget are summed into _other
+func (iw *InfluxWriter) userPoints(metrics models.WindowMetrics) []*write.Point {
+	var points []*write.Point
+	var other models.UserActivity
+
+	for i, u := range metrics.ActiveUsers {
+		if i >= iw.Limits.UsersPerWindow || !iw.userSeries.admit(u.UserID) {
+			other.Tweets += u.Tweets
+			other.Engagement += u.Engagement
+			continue
+		}
+		points = append(points, influxdb2.NewPoint(
+			"user_activity",
+			map[string]string{
+				"window":  metrics.Window,
+				"user_id": u.UserID,
+				"handle":  u.Handle,
+				"status":  u.Status,
+			},
+			map[string]interface{}{
+				"tweets":     u.Tweets,
+				"engagement": u.Engagement,
+			},
+			metrics.WindowEnd,
+		))
+	}
+
+	if other.Tweets > 0 || other.Engagement > 0 {
+		points = append(points, influxdb2.NewPoint(
+			"user_activity",
+			map[string]string{"window": metrics.Window, "user_id": OtherSeries},
+			map[string]interface{}{
+				"tweets":     other.Tweets,
+				"engagement": other.Engagement,
+			},
+			metrics.WindowEnd,
+		))
+	}
+	return points
 }
@@ -0,0 +1,52 @@
+package storage
+
+import "sync"
+
+// OtherSeries is the tag value that series over the cardinality limit are folded into
+const OtherSeries = "_other"
+
+type SeriesLimits struct {
+	HashtagsPerWindow int // hashtag_counts points written per window
+	UsersPerWindow    int // user_activity points written per window
+	MaxHashtagSeries  int // distinct hashtags ever written before new ones are folded into _other
+	MaxUserSeries     int // distinct users ever written before new ones are folded into _other
+}
+
+func DefaultSeriesLimits() SeriesLimits {
+	return SeriesLimits{
+		HashtagsPerWindow: 20,
+		UsersPerWindow:    50,
+		MaxHashtagSeries:  1000,
+		MaxUserSeries:     500,
+	}
+}
+
+// seriesBudget remembers which tag values already have a series
+// and admits new ones only while the budget lasts
+type seriesBudget struct {
+	mu    sync.Mutex
+	max   int
+	known map[string]struct{}
+}
+
+func newSeriesBudget(max int) *seriesBudget {
+	return &seriesBudget{
+		max:   max,
+		known: make(map[string]struct{}),
+	}
+}
+
+// admit reports whether value may be written as its own series
+func (b *seriesBudget) admit(value string) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if _, ok := b.known[value]; ok {
+		return true
+	}
+	if len(b.known) >= b.max {
+		return false
+	}
+	b.known[value] = struct{}{}
+	return true
+}
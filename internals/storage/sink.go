@@ -0,0 +1,240 @@
+package storage
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/Udehlee/tweet-stream/models"
+)
+
+// MetricsSink receives the metrics of every processed window
+type MetricsSink interface {
+	Insert(metrics models.WindowMetrics) error
+	Close() error
+}
+
+// FanoutSink writes every window to all of its sinks
+type FanoutSink struct {
+	Sinks []MetricsSink
+}
+
+func NewFanoutSink(sinks ...MetricsSink) *FanoutSink {
+	return &FanoutSink{Sinks: sinks}
+}
+
+// Insert writes to every sink even when some of them fail
+func (f *FanoutSink) Insert(metrics models.WindowMetrics) error {
+	var errs []error
+	for _, sink := range f.Sinks {
+		if err := sink.Insert(metrics); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Close closes every sink
+func (f *FanoutSink) Close() error {
+	var errs []error
+	for _, sink := range f.Sinks {
+		if err := sink.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// MemorySink keeps every window in memory, it is meant for tests
+type MemorySink struct {
+	mu      sync.Mutex
+	metrics []models.WindowMetrics
+}
+
+func NewMemorySink() *MemorySink {
+	return &MemorySink{}
+}
+
+func (m *MemorySink) Insert(metrics models.WindowMetrics) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.metrics = append(m.metrics, metrics)
+	return nil
+}
+
+// Metrics returns a copy of every window received so far
+func (m *MemorySink) Metrics() []models.WindowMetrics {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return append([]models.WindowMetrics(nil), m.metrics...)
+}
+
+// Reset forgets every window received so far
+func (m *MemorySink) Reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.metrics = nil
+}
+
+func (m *MemorySink) Close() error {
+	return nil
+}
+
+// BroadcastSink hands every window to the subscribers listening at the time
+// a subscriber that falls behind misses windows instead of slowing the aggregator
+type BroadcastSink struct {
+	mu     sync.Mutex
+	subs   map[chan models.WindowMetrics]struct{}
+	closed bool
+}
+
+func NewBroadcastSink() *BroadcastSink {
+	return &BroadcastSink{subs: make(map[chan models.WindowMetrics]struct{})}
+}
+
+// Subscribe returns the windows inserted from now on and a cancel func
+// the channel is closed by cancel or when the sink closes
+func (b *BroadcastSink) Subscribe(buffer int) (<-chan models.WindowMetrics, func()) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	ch := make(chan models.WindowMetrics, buffer)
+	if b.closed {
+		close(ch)
+		return ch, func() {}
+	}
+
+	b.subs[ch] = struct{}{}
+	return ch, func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+
+		if _, ok := b.subs[ch]; ok {
+			delete(b.subs, ch)
+			close(ch)
+		}
+	}
+}
+
+// Insert hands the window to every subscriber
+func (b *BroadcastSink) Insert(metrics models.WindowMetrics) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for ch := range b.subs {
+		select {
+		case ch <- metrics:
+		default:
+		}
+	}
+	return nil
+}
+
+// Close ends every subscription
+func (b *BroadcastSink) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for ch := range b.subs {
+		delete(b.subs, ch)
+		close(ch)
+	}
+	b.closed = true
+	return nil
+}
+
+// JSONFileSink appends every window to a newline delimited JSON file
+type JSONFileSink struct {
+	mu     sync.Mutex
+	file   *os.File
+	writer *bufio.Writer
+}
+
+func NewJSONFileSink(path string) (*JSONFileSink, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open metrics file: %w", err)
+	}
+
+	return &JSONFileSink{
+		file:   file,
+		writer: bufio.NewWriter(file),
+	}, nil
+}
+
+// Insert writes the window as a single JSON line
+func (j *JSONFileSink) Insert(metrics models.WindowMetrics) error {
+	data, err := json.Marshal(metrics)
+	if err != nil {
+		return fmt.Errorf("failed to encode metrics: %w", err)
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if _, err := j.writer.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write metrics: %w", err)
+	}
+	return j.writer.Flush()
+}
+
+// Close flushes and closes the file
+func (j *JSONFileSink) Close() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if err := j.writer.Flush(); err != nil {
+		j.file.Close()
+		return err
+	}
+	return j.file.Close()
+}
+
+// NewSink builds the sinks named in cfg, wrapping them in a FanoutSink
+// when more than one is configured
+func NewSink(cfg *SinkConfig) (MetricsSink, error) {
+	var sinks []MetricsSink
+	closeAll := func() {
+		for _, s := range sinks {
+			s.Close()
+		}
+	}
+
+	for _, name := range cfg.Sinks {
+		switch name {
+		case SinkInflux:
+			influx, err := ConnectToInfluxDB(cfg.Influx, cfg.Buffer, cfg.Limits)
+			if err != nil {
+				closeAll()
+				return nil, err
+			}
+			sinks = append(sinks, influx)
+
+		case SinkFile:
+			file, err := NewJSONFileSink(cfg.FilePath)
+			if err != nil {
+				closeAll()
+				return nil, err
+			}
+			sinks = append(sinks, file)
+
+		case SinkMemory:
+			sinks = append(sinks, NewMemorySink())
+
+		default:
+			closeAll()
+			return nil, fmt.Errorf("unknown metrics sink %q", name)
+		}
+	}
+
+	if len(sinks) == 1 {
+		return sinks[0], nil
+	}
+	return NewFanoutSink(sinks...), nil
+}
@@ -0,0 +1,117 @@
+// Package trending tracks hashtag popularity over long horizons in bounded memory
+package trending
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Udehlee/tweet-stream/internals/sketch"
+	"github.com/Udehlee/tweet-stream/models"
+)
+
+type Config struct {
+	Capacity      int           // hashtags tracked per horizon
+	ShortHalfLife time.Duration // horizon of "trending now"
+	LongHalfLife  time.Duration // horizon of the baseline
+	MinCount      float64       // decayed uses in the short horizon before a tag can trend
+}
+
+func DefaultConfig() Config {
+	return Config{
+		Capacity:      1000,
+		ShortHalfLife: 5 * time.Minute,
+		LongHalfLife:  time.Hour,
+		MinCount:      3,
+	}
+}
+
+// Tracker compares how often hashtags are used now against their baseline
+type Tracker struct {
+	mu    sync.Mutex
+	cfg   Config
+	short *sketch.SpaceSaving
+	long  *sketch.SpaceSaving
+}
+
+func NewTracker(cfg Config) *Tracker {
+	def := DefaultConfig()
+	if cfg.Capacity <= 0 {
+		cfg.Capacity = def.Capacity
+	}
+	if cfg.ShortHalfLife <= 0 {
+		cfg.ShortHalfLife = def.ShortHalfLife
+	}
+	if cfg.LongHalfLife <= cfg.ShortHalfLife {
+		cfg.LongHalfLife = 12 * cfg.ShortHalfLife
+	}
+	if cfg.MinCount <= 0 {
+		cfg.MinCount = def.MinCount
+	}
+
+	return &Tracker{
+		cfg:   cfg,
+		short: sketch.NewSpaceSaving(cfg.Capacity, cfg.ShortHalfLife),
+		long:  sketch.NewSpaceSaving(cfg.Capacity, cfg.LongHalfLife),
+	}
+}
+
+// Observe counts a use of tag at ts
+func (t *Tracker) Observe(tag string, ts time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.short.Add(tag, ts)
+	t.long.Add(tag, ts)
+}
+
+// Trending returns up to n hashtags ranked by velocity as of now, every one when n is negative
+// velocity is the current rate over the baseline rate, both per minute
+// a tag with no history is measured against one use per long half life
+func (t *Tracker) Trending(n int, now time.Time) []models.TrendingHashtag {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	shortRate := math.Ln2 / t.cfg.ShortHalfLife.Minutes()
+	longRate := math.Ln2 / t.cfg.LongHalfLife.Minutes()
+	prior := longRate
+
+	var tags []models.TrendingHashtag
+	for _, item := range t.short.Top(-1, now) {
+		if item.Count < t.cfg.MinCount {
+			break
+		}
+		rate := item.Count * shortRate
+		baseline := t.long.Estimate(item.Key, now).Count * longRate
+
+		tags = append(tags, models.TrendingHashtag{
+			Tag:      item.Key,
+			Count:    item.Count,
+			Rate:     rate,
+			Baseline: baseline,
+			Velocity: (rate + prior) / (baseline + prior),
+		})
+	}
+
+	sort.SliceStable(tags, func(i, j int) bool {
+		return tags[i].Velocity > tags[j].Velocity
+	})
+	if n >= 0 && len(tags) > n {
+		tags = tags[:n]
+	}
+	return tags
+}
+
+// Top returns up to n hashtags most used over the long horizon
+func (t *Tracker) Top(n int, now time.Time) []models.HashtagCount {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	items := t.long.Top(n, now)
+	tags := make([]models.HashtagCount, len(items))
+	for i, item := range items {
+		tags[i] = models.HashtagCount{Tag: item.Key, Count: int(math.Round(item.Count))}
+	}
+	return tags
+}
@@ -0,0 +1,38 @@
+package trending
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTrendingLimit(t *testing.T) {
+	tracker := NewTracker(Config{MinCount: 1})
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for _, tag := range []string{"go", "go", "go", "rust", "rust", "zig"} {
+		tracker.Observe(tag, now)
+	}
+
+	for _, tt := range []struct{ n, want int }{{-1, 3}, {0, 0}, {2, 2}, {10, 3}} {
+		if got := len(tracker.Trending(tt.n, now)); got != tt.want {
+			t.Errorf("Trending(%d) returned %d tags, want %d", tt.n, got, tt.want)
+		}
+		if got := len(tracker.Top(tt.n, now)); got != tt.want {
+			t.Errorf("Top(%d) returned %d tags, want %d", tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestTrendingAfterLongIdle(t *testing.T) {
+	tracker := NewTracker(Config{ShortHalfLife: time.Second, LongHalfLife: 10 * time.Second, MinCount: 1})
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	tracker.Observe("old", now)
+
+	later := now.Add(24 * time.Hour)
+	tracker.Observe("new", later)
+	tracker.Observe("new", later)
+
+	tags := tracker.Trending(-1, later)
+	if len(tags) != 1 || tags[0].Tag != "new" || tags[0].Count < 1.99 {
+		t.Errorf("trending after a day of quiet = %+v, want only new", tags)
+	}
+}
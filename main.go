@@ -2,77 +2,296 @@ package main
 
 import (
 	"context"
+	"errors"
+	"flag"
 	"net"
+	"net/http"
 	"os"
-	"time"
+	"os/signal"
+	"syscall"
 
 	"github.com/Udehlee/tweet-stream/gapi"
+	"github.com/Udehlee/tweet-stream/gateway"
 	"github.com/Udehlee/tweet-stream/internals/aggregator"
+	"github.com/Udehlee/tweet-stream/internals/config"
 	"github.com/Udehlee/tweet-stream/internals/data/client"
 	"github.com/Udehlee/tweet-stream/internals/data/generator"
 	"github.com/Udehlee/tweet-stream/internals/data/simulated"
+	"github.com/Udehlee/tweet-stream/internals/events"
+	"github.com/Udehlee/tweet-stream/internals/hub"
+	"github.com/Udehlee/tweet-stream/internals/lifecycle"
 	"github.com/Udehlee/tweet-stream/internals/processor"
+	"github.com/Udehlee/tweet-stream/internals/scenario"
 	"github.com/Udehlee/tweet-stream/internals/storage"
 	"github.com/Udehlee/tweet-stream/models"
 	"github.com/Udehlee/tweet-stream/pb"
+	"github.com/Udehlee/tweet-stream/utils"
 	"github.com/rs/zerolog"
 	"google.golang.org/grpc"
 )
 
 func main() {
+	flags := config.RegisterFlags(flag.CommandLine)
+	flag.Parse()
+
 	logger := zerolog.New(os.Stdout).With().Timestamp().Logger()
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
+	ctx, stopSignals := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stopSignals()
 
-	influxDB, err := storage.ConnectToInfluxDB()
+	cfg, err := config.Load(flags.Path, flags)
 	if err != nil {
-		logger.Fatal().Err(err).Msg("Failed to connect to InfluxDB")
+		logger.Fatal().Err(err).Msg("Invalid configuration")
+	}
+
+	level, _ := zerolog.ParseLevel(cfg.Log.Level)
+	zerolog.SetGlobalLevel(level)
+
+	sink, err := storage.NewSink(cfg.SinkConfig())
+	if err != nil {
+		logger.Fatal().Err(err).Msg("Failed to set up metrics sinks")
+	}
+	// windows are also pushed live to WebSocket clients
+	liveMetrics := storage.NewBroadcastSink()
+	sink = storage.NewFanoutSink(sink, liveMetrics)
+
+	generatedChan := make(chan *models.TweetEvent, cfg.Pipeline.GeneratedBuffer)
+	StreamChan := make(chan *models.TweetEvent, cfg.Pipeline.StreamBuffer)
+
+	src, retryRand := utils.NewSource(), utils.NewTimeRandom()
+	var cl generator.TweetSource = client.NewClient()
+	if seed := cfg.Simulation.Seed; seed != 0 {
+		// seeded runs read tweets from the offline list so the network cannot change them
+		src, retryRand = utils.NewSeededSource(seed), utils.NewRandom(seed)
+		cl = client.NewOfflineClient(src.Rand)
+		logger.Info().Msgf("Running a reproducible simulation with seed %d", seed)
 	}
-	defer influxDB.Close()
 
-	generatedChan := make(chan *models.Tweet, 50)
-	StreamChan := make(chan *models.Tweet, 50)
+	users := simulated.NewUserRegistry(cfg.Simulation.UserPoolSize, src)
+	tweetSvc := simulated.NewTweetService(&logger, users, src)
+	emitter, err := events.NewEmitter(generatedChan, src)
+	if err != nil {
+		logger.Fatal().Err(err).Msg("Failed to set up the event emitter")
+	}
+	gs := generator.NewGeneratorService(tweetSvc, cl, &logger, emitter, src)
+	gs.Engagement = cfg.Simulation.Engagement.GeneratorConfig()
+	if err := gs.SetMix(cfg.Simulation.Mix); err != nil {
+		logger.Fatal().Err(err).Msg("Invalid operation mix")
+	}
 
-	tweetSvc := simulated.NewTweetService(&logger)
-	cl := client.NewClient()
-	gs := generator.NewGeneratorService(tweetSvc, cl, &logger, generatedChan)
+	// already checked by config validation
+	policy, _ := hub.ParsePolicy(cfg.Hub.SlowConsumerPolicy)
+	retention, err := openRetentionLog(cfg.Hub.RetentionPath, cfg.Hub.RetentionSize)
+	if err != nil {
+		logger.Fatal().Err(err).Msg("Failed to open retention log")
+	}
 
-	StartgRPCServer(generatedChan, &logger, ":50051")
-	StartProcessor(ctx, "localhost:50051", StreamChan, &logger)
+	// every component gets its own context so shutdown can stop them one at a time
+	hubCtx, stopHub := context.WithCancel(context.Background())
+	procCtx, stopProcessor := context.WithCancel(context.Background())
+	genCtx, stopGenerator := context.WithCancel(context.Background())
+	aggCtx, stopAggregator := context.WithCancel(context.Background())
 
-	go gs.GenerateTweets(ctx, 1*time.Second)
+	eventHub := hub.NewHub(hub.WithBufferSize(cfg.Hub.BufferSize), hub.WithPolicy(policy), hub.WithRetentionLog(retention))
+	go eventHub.Run(hubCtx, generatedChan)
 
-	agg := aggregator.NewTweetAggregator(generatedChan, influxDB, 5*time.Second)
-	go agg.Start(ctx)
+	streamServer := gapi.NewStreamServer(eventHub, users, tweetSvc, emitter,
+		gapi.WithIdempotencyKeys(cfg.Ingest.IdempotencyKeys),
+		gapi.WithBackpressure(func() float64 {
+			return max(channelFill(generatedChan), channelFill(StreamChan))
+		}, cfg.Ingest.HighWatermark),
+	)
+	grpcServer, serveErr := StartgRPCServer(streamServer, &logger, cfg.GRPC.Addr)
+	api := gateway.NewGateway(streamServer,
+		gateway.WithKeepAlive(cfg.HTTP.KeepAlive),
+		gateway.WithHeartbeat(cfg.HTTP.Heartbeat),
+		gateway.WithMetrics(liveMetrics),
+	)
+	httpServer, httpErr := StartHTTPServer(api, &logger, cfg.HTTP.Addr)
+	streamProcessor := StartProcessor(procCtx, cfg.GRPC.ProcessorTarget, StreamChan, retryRand, &logger)
+
+	if path := cfg.Simulation.Scenario; path != "" {
+		sc, err := scenario.Load(path)
+		if err != nil {
+			logger.Fatal().Err(err).Msg("Failed to load scenario")
+		}
+		go scenario.NewRunner(sc, gs, src.Rand, &logger).Run(genCtx)
+	} else {
+		go gs.GenerateTweets(genCtx, cfg.Simulation.Interval)
+	}
+
+	specs, err := cfg.Aggregator.WindowSpecs()
+	if err != nil {
+		logger.Fatal().Err(err).Msg("Invalid aggregation windows")
+	}
+	aggOpts := []aggregator.Option{
+		aggregator.WithWindows(specs...),
+		aggregator.WithWatermarkDelay(cfg.Aggregator.WatermarkDelay),
+		aggregator.WithAllowedLateness(cfg.Aggregator.AllowedLatenessOrDefault()),
+		aggregator.WithIdleTimeout(cfg.Aggregator.IdleTimeout),
+		aggregator.WithAnomalyConfig(cfg.Anomaly.DetectorConfig()),
+		aggregator.WithTrending(cfg.Trending.TrackerConfig()),
+		aggregator.WithTopN(cfg.Aggregator.TopHashtags, cfg.Trending.Top),
+	}
+	if cfg.Simulation.Seed != 0 {
+		aggOpts = append(aggOpts, aggregator.WithEmitTime())
+	}
+	agg := aggregator.NewTweetAggregator(StreamChan, sink, cfg.Aggregator.Window, aggOpts...)
+	go agg.Start(aggCtx)
+
+	watcher := config.NewWatcher(flags.Path, flags, cfg, &logger, func(cfg *config.Config) {
+		level, _ := zerolog.ParseLevel(cfg.Log.Level)
+		zerolog.SetGlobalLevel(level)
+		gs.SetInterval(cfg.Simulation.Interval)
+		if err := gs.SetMix(cfg.Simulation.Mix); err != nil {
+			logger.Error().Err(err).Msg("Failed to apply operation mix")
+		}
+		agg.UpdateSettings(aggregator.Settings{
+			AnomalyThreshold: cfg.Anomaly.Threshold,
+			TopHashtags:      cfg.Aggregator.TopHashtags,
+			TopTrending:      cfg.Trending.Top,
+		})
+	})
+	go watcher.Run(ctx)
 
 	logger.Info().Msg("Tweet streaming service running. Press Ctrl+C to exit.")
-	select {}
+
+	failed := false
+	select {
+	case <-ctx.Done():
+		logger.Info().Msg("Shutdown signal received, stopping")
+	case err := <-serveErr:
+		logger.Error().Err(err).Msg("Failed to serve GRPC, stopping")
+		failed = true
+	case err := <-httpErr:
+		logger.Error().Err(err).Msg("Failed to serve HTTP, stopping")
+		failed = true
+	}
+	stopSignals() // a second signal kills the process right away
+
+	// upstream first so every event already emitted makes it into the last windows
+	shutdown := lifecycle.NewShutdown(&logger)
+	shutdown.Add("generator", func(ctx context.Context) error {
+		stopGenerator()
+		return gs.Stop(ctx)
+	})
+	shutdown.Add("hub", lifecycle.WaitFor(stopHub, eventHub.Done()))
+	shutdown.Add("grpc server", gracefulStop(grpcServer))
+	if httpServer != nil {
+		// event streams and WebSockets have ended with the hub, so only plain requests are left
+		shutdown.Add("http server", shutdownHTTP(httpServer))
+	}
+	shutdown.Add("processor", func(ctx context.Context) error {
+		defer stopProcessor()
+		return streamProcessor.Stop(ctx)
+	})
+	shutdown.Add("aggregator", lifecycle.WaitFor(stopAggregator, agg.Done()))
+	shutdown.Add("metrics sinks", func(context.Context) error { return sink.Close() })
+	shutdown.Add("retention log", func(context.Context) error { return retention.Close() })
+
+	if err := shutdown.Run(cfg.Shutdown.Timeout); err != nil {
+		logger.Error().Err(err).Msg("Shutdown finished with errors")
+		os.Exit(1)
+	}
+	if failed {
+		os.Exit(1)
+	}
+	logger.Info().Msg("Shutdown complete")
 }
 
-func StartgRPCServer(tweetChan <-chan *models.Tweet, logger *zerolog.Logger, port string) {
+// StartgRPCServer serves in the background, the channel reports a server that stopped on its own
+func StartgRPCServer(streamServer *gapi.StreamServer, logger *zerolog.Logger, port string) (*grpc.Server, <-chan error) {
 	lis, err := net.Listen("tcp", port)
 	if err != nil {
 		logger.Fatal().Err(err).Msg("Failed to listen on GRPC port")
 	}
 
-	streamServer := gapi.NewStreamServer(tweetChan)
 	grpcServer := grpc.NewServer()
 	pb.RegisterTweetServiceServer(grpcServer, streamServer)
 
+	serveErr := make(chan error, 1)
 	go func() {
 		logger.Info().Msgf("GRPC server started on %s", port)
+		// Serve only returns nil after GracefulStop or Stop
 		if err := grpcServer.Serve(lis); err != nil {
-			logger.Fatal().Err(err).Msg("Failed to serve GRPC")
+			serveErr <- err
 		}
 	}()
+	return grpcServer, serveErr
+}
+
+// gracefulStop waits for open streams to finish, cutting them off at the deadline
+func gracefulStop(server *grpc.Server) lifecycle.StopFunc {
+	return func(ctx context.Context) error {
+		stopped := make(chan struct{})
+		go func() {
+			server.GracefulStop()
+			close(stopped)
+		}()
+
+		select {
+		case <-stopped:
+			return nil
+		case <-ctx.Done():
+			server.Stop()
+			return ctx.Err()
+		}
+	}
+}
+
+// StartHTTPServer serves the gateway in the background, an empty addr disables it
+// and returns a nil server and a channel that never fires
+func StartHTTPServer(handler http.Handler, logger *zerolog.Logger, addr string) (*http.Server, <-chan error) {
+	if addr == "" {
+		return nil, nil
+	}
+
+	lis, err := net.Listen("tcp", addr)
+	if err != nil {
+		logger.Fatal().Err(err).Msg("Failed to listen on HTTP port")
+	}
+
+	server := &http.Server{Addr: addr, Handler: handler}
+	serveErr := make(chan error, 1)
+	go func() {
+		logger.Info().Msgf("HTTP server started on %s", addr)
+		if err := server.Serve(lis); !errors.Is(err, http.ErrServerClosed) {
+			serveErr <- err
+		}
+	}()
+	return server, serveErr
+}
+
+// shutdownHTTP waits for in flight requests, closing the connections at the deadline
+func shutdownHTTP(server *http.Server) lifecycle.StopFunc {
+	return func(ctx context.Context) error {
+		if err := server.Shutdown(ctx); err != nil {
+			server.Close()
+			return err
+		}
+		return nil
+	}
+}
+
+// channelFill reports how full a channel buffer is, from 0 to 1
+func channelFill(ch chan *models.TweetEvent) float64 {
+	return float64(len(ch)) / float64(cap(ch))
+}
+
+// openRetentionLog keeps the log in memory unless a spool path is set
+func openRetentionLog(path string, capacity int) (*hub.RetentionLog, error) {
+	if path == "" {
+		return hub.NewRetentionLog(capacity), nil
+	}
+	return hub.OpenRetentionLog(capacity, path)
 }
 
-func StartProcessor(ctx context.Context, grpcTarget string, aggChan chan<- *models.Tweet, logger *zerolog.Logger) {
-	processor := processor.NewProcessor(grpcTarget, aggChan)
+func StartProcessor(ctx context.Context, grpcTarget string, aggChan chan<- *models.TweetEvent, r *utils.Random, logger *zerolog.Logger) *processor.StreamProcessor {
+	processor := processor.NewProcessor(grpcTarget, aggChan, r)
 
 	go func() {
 		if err := processor.Run(ctx); err != nil {
 			logger.Error().Err(err).Msg("StreamProcessor exited with error")
 		}
 	}()
+	return processor
 }
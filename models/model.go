@@ -1,6 +1,10 @@
 package models
 
-import "time"
+import (
+	"time"
+
+	"github.com/Udehlee/tweet-stream/internals/sketch"
+)
 
 // Tweet holds the current tweet data of a single tweet
 type Tweet struct {
@@ -8,16 +12,80 @@ type Tweet struct {
 	User      *User
 	Message   string
 	HashTag   []string
+	Mentions  []string
+	URLs      []string
+	Cashtags  []string
 	Reactions []Reaction
 	Comments  []Comment
 	UpdatedAt time.Time
 	CreatedAt time.Time
 }
 
+// Clone returns a copy of the tweet that can be handed to other goroutines
+// without sharing the reaction and comment slices
+func (t *Tweet) Clone() *Tweet {
+	if t == nil {
+		return nil
+	}
+	c := *t
+	c.HashTag = append([]string(nil), t.HashTag...)
+	c.Mentions = append([]string(nil), t.Mentions...)
+	c.URLs = append([]string(nil), t.URLs...)
+	c.Cashtags = append([]string(nil), t.Cashtags...)
+	c.Reactions = append([]Reaction(nil), t.Reactions...)
+	c.Comments = append([]Comment(nil), t.Comments...)
+	return &c
+}
+
+// Engagement sums likes, retweets and comments on the tweet
+func (t *Tweet) Engagement() int {
+	if t == nil {
+		return 0
+	}
+	total := len(t.Comments)
+	for _, r := range t.Reactions {
+		if r.ReactionType == ReactionLike || r.ReactionType == ReactionRetweet {
+			total += r.Count
+		}
+	}
+	return total
+}
+
+// EventType tells consumers what happened to a tweet
+type EventType string
+
+const (
+	EventCreated EventType = "CREATED"
+	EventUpdated EventType = "UPDATED"
+	EventDeleted EventType = "DELETED"
+)
+
+// TweetEvent is the envelope carried on the stream for every tweet change
+// Previous holds the tweet as it was before an update
+// Offset is the position of the event in the server retention log
+type TweetEvent struct {
+	EventID   string
+	Type      EventType
+	Sequence  uint64
+	Offset    uint64
+	Tweet     *Tweet
+	Previous  *Tweet
+	EmittedAt time.Time
+}
+
+// User is a simulated account
+// Status is either verified or unverified
+// ActivityLevel weighs how often the user posts and reacts
 type User struct {
-	UserID string
-	Name   string
-	Status string
+	UserID         string
+	Name           string
+	Status         string
+	Handle         string
+	DisplayName    string
+	CreatedAt      time.Time
+	FollowerCount  int
+	FollowingCount int
+	ActivityLevel  float64
 }
 
 //Reactions holds the reactions to a single tweet
@@ -28,6 +96,11 @@ type Reaction struct {
 	Count        int
 }
 
+const (
+	ReactionLike    = "like"
+	ReactionRetweet = "retweet"
+)
+
 //Comments holds individual comment to a post
 type Comment struct {
 	User     *User
@@ -40,24 +113,87 @@ type Quote struct {
 }
 
 // WindowMetrics holds all calculated results for a single time window
+// windows are keyed on event time, a window that receives late events
+// is emitted again with a higher Revision
+// Window names the window definition, Key is the user of a session window
 type WindowMetrics struct {
-	WindowStart      time.Time
-	WindowEnd        time.Time
-	TotalTweets      int
-	TrendingHashtags []HashtagCount
-	TotalEngagement  int
-	VerifiedCount    int
-	UnverifiedCount  int
+	Window           string            `json:"window"`
+	Key              string            `json:"key,omitempty"`
+	WindowStart      time.Time         `json:"window_start"`
+	WindowEnd        time.Time         `json:"window_end"`
+	TotalEvents      int               `json:"total_events"`
+	TotalTweets      int               `json:"total_tweets"` // new tweets created in the window
+	UpdatedTweets    int               `json:"updated_tweets"`
+	DeletedTweets    int               `json:"deleted_tweets"`
+	TrendingHashtags []HashtagCount    `json:"trending_hashtags"`
+	HashtagCounts    []HashtagCount    `json:"hashtag_counts"` // every hashtag seen in the window, most used first
+	TrendingNow      []TrendingHashtag `json:"trending_now"`   // long horizon trends ranked by velocity
+	ActiveUsers      []UserActivity    `json:"active_users"`   // users that tweeted or were engaged with, most active first
+	TotalEngagement  int               `json:"total_engagement"`
+	VerifiedCount    int               `json:"verified_count"`
+	UnverifiedCount  int               `json:"unverified_count"`
 
 	// Latency Metrics
-	AvgLatency time.Duration
-	MaxLatency time.Duration
-	MinLatency time.Duration
+	// LatencySketch holds the latency distribution in nanoseconds
+	// so windows can be merged into longer rollups
+	AvgLatency    time.Duration    `json:"avg_latency_ns"`
+	MaxLatency    time.Duration    `json:"max_latency_ns"`
+	MinLatency    time.Duration    `json:"min_latency_ns"`
+	P50Latency    time.Duration    `json:"p50_latency_ns"`
+	P90Latency    time.Duration    `json:"p90_latency_ns"`
+	P99Latency    time.Duration    `json:"p99_latency_ns"`
+	P999Latency   time.Duration    `json:"p999_latency_ns"`
+	LatencySketch *sketch.DDSketch `json:"latency_sketch,omitempty"`
+
+	// Event time bookkeeping
+	Watermark   time.Time `json:"watermark"`
+	Revision    int       `json:"revision"`
+	LateEvents  int       `json:"late_events"`  // events accepted after the window first closed
+	LateDropped int       `json:"late_dropped"` // events dropped past the allowed lateness since startup
+
+	IsAnomaly bool      `json:"is_anomaly"`
+	Anomalies []Anomaly `json:"anomalies,omitempty"`
+}
+
+// Anomaly directions
+const (
+	AnomalySpike = "spike"
+	AnomalyDrop  = "drop"
+)
 
-	IsAnomaly bool
+// Anomaly is a metric that strayed from its baseline
+// Score is how many deviations Value is from Expected, negative for drops
+type Anomaly struct {
+	Metric    string  `json:"metric"`
+	Method    string  `json:"method"`
+	Direction string  `json:"direction"`
+	Severity  string  `json:"severity"`
+	Value     float64 `json:"value"`
+	Expected  float64 `json:"expected"`
+	Score     float64 `json:"score"`
 }
 
 type HashtagCount struct {
-	Tag   string
-	Count int
+	Tag   string `json:"tag"`
+	Count int    `json:"count"`
+}
+
+// TrendingHashtag compares how often a hashtag is used now against its baseline
+// Count is the decayed number of recent uses, rates are uses per minute
+type TrendingHashtag struct {
+	Tag      string  `json:"tag"`
+	Count    float64 `json:"count"`
+	Rate     float64 `json:"rate"`
+	Baseline float64 `json:"baseline"`
+	Velocity float64 `json:"velocity"`
+}
+
+// UserActivity is what a single user did in a window
+// Engagement is what their tweets received
+type UserActivity struct {
+	UserID     string `json:"user_id"`
+	Handle     string `json:"handle"`
+	Status     string `json:"status"`
+	Tweets     int    `json:"tweets"`
+	Engagement int    `json:"engagement"`
 }
@@ -9,6 +9,7 @@ package pb
 import (
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
 	reflect "reflect"
 	sync "sync"
 	unsafe "unsafe"
@@ -57,14 +58,1261 @@ func (*Empty) Descriptor() ([]byte, []int) {
 	return file_service_tweet_stream_proto_rawDescGZIP(), []int{0}
 }
 
+// StreamRequest narrows the stream down to matching events.
+// Every non-empty filter must match, values inside a filter are alternatives.
+type StreamRequest struct {
+	state        protoimpl.MessageState `protogen:"open.v1"`
+	Hashtags     []string               `protobuf:"bytes,1,rep,name=hashtags,proto3" json:"hashtags,omitempty"`
+	UserIds      []string               `protobuf:"bytes,2,rep,name=user_ids,json=userIds,proto3" json:"user_ids,omitempty"`
+	VerifiedOnly bool                   `protobuf:"varint,3,opt,name=verified_only,json=verifiedOnly,proto3" json:"verified_only,omitempty"`
+	Keywords     []string               `protobuf:"bytes,4,rep,name=keywords,proto3" json:"keywords,omitempty"`
+	MessageRegex string                 `protobuf:"bytes,5,opt,name=message_regex,json=messageRegex,proto3" json:"message_regex,omitempty"`
+	EventTypes   []EventType            `protobuf:"varint,6,rep,packed,name=event_types,json=eventTypes,proto3,enum=event.EventType" json:"event_types,omitempty"`
+	// resume_from_offset replays retained events starting at this offset
+	// before switching to the live stream
+	ResumeFromOffset *uint64 `protobuf:"varint,7,opt,name=resume_from_offset,json=resumeFromOffset,proto3,oneof" json:"resume_from_offset,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *StreamRequest) Reset() {
+	*x = StreamRequest{}
+	mi := &file_service_tweet_stream_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StreamRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamRequest) ProtoMessage() {}
+
+func (x *StreamRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_service_tweet_stream_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamRequest.ProtoReflect.Descriptor instead.
+func (*StreamRequest) Descriptor() ([]byte, []int) {
+	return file_service_tweet_stream_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *StreamRequest) GetHashtags() []string {
+	if x != nil {
+		return x.Hashtags
+	}
+	return nil
+}
+
+func (x *StreamRequest) GetUserIds() []string {
+	if x != nil {
+		return x.UserIds
+	}
+	return nil
+}
+
+func (x *StreamRequest) GetVerifiedOnly() bool {
+	if x != nil {
+		return x.VerifiedOnly
+	}
+	return false
+}
+
+func (x *StreamRequest) GetKeywords() []string {
+	if x != nil {
+		return x.Keywords
+	}
+	return nil
+}
+
+func (x *StreamRequest) GetMessageRegex() string {
+	if x != nil {
+		return x.MessageRegex
+	}
+	return ""
+}
+
+func (x *StreamRequest) GetEventTypes() []EventType {
+	if x != nil {
+		return x.EventTypes
+	}
+	return nil
+}
+
+func (x *StreamRequest) GetResumeFromOffset() uint64 {
+	if x != nil && x.ResumeFromOffset != nil {
+		return *x.ResumeFromOffset
+	}
+	return 0
+}
+
+type GetUserRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetUserRequest) Reset() {
+	*x = GetUserRequest{}
+	mi := &file_service_tweet_stream_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetUserRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetUserRequest) ProtoMessage() {}
+
+func (x *GetUserRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_service_tweet_stream_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetUserRequest.ProtoReflect.Descriptor instead.
+func (*GetUserRequest) Descriptor() ([]byte, []int) {
+	return file_service_tweet_stream_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *GetUserRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+type ListUsersRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PageSize      int32                  `protobuf:"varint,1,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	PageToken     string                 `protobuf:"bytes,2,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListUsersRequest) Reset() {
+	*x = ListUsersRequest{}
+	mi := &file_service_tweet_stream_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListUsersRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListUsersRequest) ProtoMessage() {}
+
+func (x *ListUsersRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_service_tweet_stream_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListUsersRequest.ProtoReflect.Descriptor instead.
+func (*ListUsersRequest) Descriptor() ([]byte, []int) {
+	return file_service_tweet_stream_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ListUsersRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+func (x *ListUsersRequest) GetPageToken() string {
+	if x != nil {
+		return x.PageToken
+	}
+	return ""
+}
+
+type ListFollowersRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	PageSize      int32                  `protobuf:"varint,2,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	PageToken     string                 `protobuf:"bytes,3,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListFollowersRequest) Reset() {
+	*x = ListFollowersRequest{}
+	mi := &file_service_tweet_stream_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListFollowersRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListFollowersRequest) ProtoMessage() {}
+
+func (x *ListFollowersRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_service_tweet_stream_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListFollowersRequest.ProtoReflect.Descriptor instead.
+func (*ListFollowersRequest) Descriptor() ([]byte, []int) {
+	return file_service_tweet_stream_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *ListFollowersRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *ListFollowersRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+func (x *ListFollowersRequest) GetPageToken() string {
+	if x != nil {
+		return x.PageToken
+	}
+	return ""
+}
+
+type ListFollowingRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	PageSize      int32                  `protobuf:"varint,2,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	PageToken     string                 `protobuf:"bytes,3,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListFollowingRequest) Reset() {
+	*x = ListFollowingRequest{}
+	mi := &file_service_tweet_stream_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListFollowingRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListFollowingRequest) ProtoMessage() {}
+
+func (x *ListFollowingRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_service_tweet_stream_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListFollowingRequest.ProtoReflect.Descriptor instead.
+func (*ListFollowingRequest) Descriptor() ([]byte, []int) {
+	return file_service_tweet_stream_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *ListFollowingRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *ListFollowingRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+func (x *ListFollowingRequest) GetPageToken() string {
+	if x != nil {
+		return x.PageToken
+	}
+	return ""
+}
+
+type ListUsersResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Users         []*User                `protobuf:"bytes,1,rep,name=users,proto3" json:"users,omitempty"`
+	NextPageToken string                 `protobuf:"bytes,2,opt,name=next_page_token,json=nextPageToken,proto3" json:"next_page_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListUsersResponse) Reset() {
+	*x = ListUsersResponse{}
+	mi := &file_service_tweet_stream_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListUsersResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListUsersResponse) ProtoMessage() {}
+
+func (x *ListUsersResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_service_tweet_stream_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListUsersResponse.ProtoReflect.Descriptor instead.
+func (*ListUsersResponse) Descriptor() ([]byte, []int) {
+	return file_service_tweet_stream_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *ListUsersResponse) GetUsers() []*User {
+	if x != nil {
+		return x.Users
+	}
+	return nil
+}
+
+func (x *ListUsersResponse) GetNextPageToken() string {
+	if x != nil {
+		return x.NextPageToken
+	}
+	return ""
+}
+
+type GetTweetRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetTweetRequest) Reset() {
+	*x = GetTweetRequest{}
+	mi := &file_service_tweet_stream_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetTweetRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetTweetRequest) ProtoMessage() {}
+
+func (x *GetTweetRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_service_tweet_stream_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetTweetRequest.ProtoReflect.Descriptor instead.
+func (*GetTweetRequest) Descriptor() ([]byte, []int) {
+	return file_service_tweet_stream_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *GetTweetRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+// ListTweetsRequest pages through live tweets in creation order.
+// Every non-empty filter must match, created_after is inclusive and
+// created_before exclusive.
+type ListTweetsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Hashtag       string                 `protobuf:"bytes,2,opt,name=hashtag,proto3" json:"hashtag,omitempty"`
+	CreatedAfter  *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=created_after,json=createdAfter,proto3" json:"created_after,omitempty"`
+	CreatedBefore *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=created_before,json=createdBefore,proto3" json:"created_before,omitempty"`
+	PageSize      int32                  `protobuf:"varint,5,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	PageToken     string                 `protobuf:"bytes,6,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListTweetsRequest) Reset() {
+	*x = ListTweetsRequest{}
+	mi := &file_service_tweet_stream_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListTweetsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListTweetsRequest) ProtoMessage() {}
+
+func (x *ListTweetsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_service_tweet_stream_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListTweetsRequest.ProtoReflect.Descriptor instead.
+func (*ListTweetsRequest) Descriptor() ([]byte, []int) {
+	return file_service_tweet_stream_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *ListTweetsRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *ListTweetsRequest) GetHashtag() string {
+	if x != nil {
+		return x.Hashtag
+	}
+	return ""
+}
+
+func (x *ListTweetsRequest) GetCreatedAfter() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAfter
+	}
+	return nil
+}
+
+func (x *ListTweetsRequest) GetCreatedBefore() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedBefore
+	}
+	return nil
+}
+
+func (x *ListTweetsRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+func (x *ListTweetsRequest) GetPageToken() string {
+	if x != nil {
+		return x.PageToken
+	}
+	return ""
+}
+
+type ListTweetsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Tweets        []*Tweet               `protobuf:"bytes,1,rep,name=tweets,proto3" json:"tweets,omitempty"`
+	NextPageToken string                 `protobuf:"bytes,2,opt,name=next_page_token,json=nextPageToken,proto3" json:"next_page_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListTweetsResponse) Reset() {
+	*x = ListTweetsResponse{}
+	mi := &file_service_tweet_stream_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListTweetsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListTweetsResponse) ProtoMessage() {}
+
+func (x *ListTweetsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_service_tweet_stream_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListTweetsResponse.ProtoReflect.Descriptor instead.
+func (*ListTweetsResponse) Descriptor() ([]byte, []int) {
+	return file_service_tweet_stream_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *ListTweetsResponse) GetTweets() []*Tweet {
+	if x != nil {
+		return x.Tweets
+	}
+	return nil
+}
+
+func (x *ListTweetsResponse) GetNextPageToken() string {
+	if x != nil {
+		return x.NextPageToken
+	}
+	return ""
+}
+
+// CountTweetsRequest takes the same filters as ListTweetsRequest.
+type CountTweetsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Hashtag       string                 `protobuf:"bytes,2,opt,name=hashtag,proto3" json:"hashtag,omitempty"`
+	CreatedAfter  *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=created_after,json=createdAfter,proto3" json:"created_after,omitempty"`
+	CreatedBefore *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=created_before,json=createdBefore,proto3" json:"created_before,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CountTweetsRequest) Reset() {
+	*x = CountTweetsRequest{}
+	mi := &file_service_tweet_stream_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CountTweetsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CountTweetsRequest) ProtoMessage() {}
+
+func (x *CountTweetsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_service_tweet_stream_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CountTweetsRequest.ProtoReflect.Descriptor instead.
+func (*CountTweetsRequest) Descriptor() ([]byte, []int) {
+	return file_service_tweet_stream_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *CountTweetsRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *CountTweetsRequest) GetHashtag() string {
+	if x != nil {
+		return x.Hashtag
+	}
+	return ""
+}
+
+func (x *CountTweetsRequest) GetCreatedAfter() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAfter
+	}
+	return nil
+}
+
+func (x *CountTweetsRequest) GetCreatedBefore() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedBefore
+	}
+	return nil
+}
+
+type CountTweetsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Count         int64                  `protobuf:"varint,1,opt,name=count,proto3" json:"count,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CountTweetsResponse) Reset() {
+	*x = CountTweetsResponse{}
+	mi := &file_service_tweet_stream_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CountTweetsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CountTweetsResponse) ProtoMessage() {}
+
+func (x *CountTweetsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_service_tweet_stream_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CountTweetsResponse.ProtoReflect.Descriptor instead.
+func (*CountTweetsResponse) Descriptor() ([]byte, []int) {
+	return file_service_tweet_stream_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *CountTweetsResponse) GetCount() int64 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+// CreateTweetRequest posts a tweet, an empty user_id picks the author
+// from the simulated pool the way generated tweets do.
+type CreateTweetRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateTweetRequest) Reset() {
+	*x = CreateTweetRequest{}
+	mi := &file_service_tweet_stream_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateTweetRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateTweetRequest) ProtoMessage() {}
+
+func (x *CreateTweetRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_service_tweet_stream_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateTweetRequest.ProtoReflect.Descriptor instead.
+func (*CreateTweetRequest) Descriptor() ([]byte, []int) {
+	return file_service_tweet_stream_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *CreateTweetRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *CreateTweetRequest) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+type UpdateTweetRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateTweetRequest) Reset() {
+	*x = UpdateTweetRequest{}
+	mi := &file_service_tweet_stream_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateTweetRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateTweetRequest) ProtoMessage() {}
+
+func (x *UpdateTweetRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_service_tweet_stream_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateTweetRequest.ProtoReflect.Descriptor instead.
+func (*UpdateTweetRequest) Descriptor() ([]byte, []int) {
+	return file_service_tweet_stream_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *UpdateTweetRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *UpdateTweetRequest) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+type DeleteTweetRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteTweetRequest) Reset() {
+	*x = DeleteTweetRequest{}
+	mi := &file_service_tweet_stream_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteTweetRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteTweetRequest) ProtoMessage() {}
+
+func (x *DeleteTweetRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_service_tweet_stream_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteTweetRequest.ProtoReflect.Descriptor instead.
+func (*DeleteTweetRequest) Descriptor() ([]byte, []int) {
+	return file_service_tweet_stream_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *DeleteTweetRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+// AddReactionRequest adds a like or a retweet, reaction_type is "like" or "retweet".
+type AddReactionRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TweetId       string                 `protobuf:"bytes,1,opt,name=tweet_id,json=tweetId,proto3" json:"tweet_id,omitempty"`
+	UserId        string                 `protobuf:"bytes,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	ReactionType  string                 `protobuf:"bytes,3,opt,name=reaction_type,json=reactionType,proto3" json:"reaction_type,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AddReactionRequest) Reset() {
+	*x = AddReactionRequest{}
+	mi := &file_service_tweet_stream_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AddReactionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AddReactionRequest) ProtoMessage() {}
+
+func (x *AddReactionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_service_tweet_stream_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AddReactionRequest.ProtoReflect.Descriptor instead.
+func (*AddReactionRequest) Descriptor() ([]byte, []int) {
+	return file_service_tweet_stream_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *AddReactionRequest) GetTweetId() string {
+	if x != nil {
+		return x.TweetId
+	}
+	return ""
+}
+
+func (x *AddReactionRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *AddReactionRequest) GetReactionType() string {
+	if x != nil {
+		return x.ReactionType
+	}
+	return ""
+}
+
+type AddCommentRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TweetId       string                 `protobuf:"bytes,1,opt,name=tweet_id,json=tweetId,proto3" json:"tweet_id,omitempty"`
+	UserId        string                 `protobuf:"bytes,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Content       string                 `protobuf:"bytes,3,opt,name=content,proto3" json:"content,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AddCommentRequest) Reset() {
+	*x = AddCommentRequest{}
+	mi := &file_service_tweet_stream_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AddCommentRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AddCommentRequest) ProtoMessage() {}
+
+func (x *AddCommentRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_service_tweet_stream_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AddCommentRequest.ProtoReflect.Descriptor instead.
+func (*AddCommentRequest) Descriptor() ([]byte, []int) {
+	return file_service_tweet_stream_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *AddCommentRequest) GetTweetId() string {
+	if x != nil {
+		return x.TweetId
+	}
+	return ""
+}
+
+func (x *AddCommentRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *AddCommentRequest) GetContent() string {
+	if x != nil {
+		return x.Content
+	}
+	return ""
+}
+
+// IngestRequest carries one write. Requests with an idempotency_key are
+// applied at most once: resending a key the server still remembers, on any
+// stream, returns the outcome of the first attempt instead.
+type IngestRequest struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	IdempotencyKey string                 `protobuf:"bytes,1,opt,name=idempotency_key,json=idempotencyKey,proto3" json:"idempotency_key,omitempty"`
+	// Types that are valid to be assigned to Op:
+	//
+	//	*IngestRequest_Create
+	//	*IngestRequest_Update
+	//	*IngestRequest_Delete
+	//	*IngestRequest_Reaction
+	//	*IngestRequest_Comment
+	Op            isIngestRequest_Op `protobuf_oneof:"op"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *IngestRequest) Reset() {
+	*x = IngestRequest{}
+	mi := &file_service_tweet_stream_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *IngestRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*IngestRequest) ProtoMessage() {}
+
+func (x *IngestRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_service_tweet_stream_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use IngestRequest.ProtoReflect.Descriptor instead.
+func (*IngestRequest) Descriptor() ([]byte, []int) {
+	return file_service_tweet_stream_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *IngestRequest) GetIdempotencyKey() string {
+	if x != nil {
+		return x.IdempotencyKey
+	}
+	return ""
+}
+
+func (x *IngestRequest) GetOp() isIngestRequest_Op {
+	if x != nil {
+		return x.Op
+	}
+	return nil
+}
+
+func (x *IngestRequest) GetCreate() *CreateTweetRequest {
+	if x != nil {
+		if x, ok := x.Op.(*IngestRequest_Create); ok {
+			return x.Create
+		}
+	}
+	return nil
+}
+
+func (x *IngestRequest) GetUpdate() *UpdateTweetRequest {
+	if x != nil {
+		if x, ok := x.Op.(*IngestRequest_Update); ok {
+			return x.Update
+		}
+	}
+	return nil
+}
+
+func (x *IngestRequest) GetDelete() *DeleteTweetRequest {
+	if x != nil {
+		if x, ok := x.Op.(*IngestRequest_Delete); ok {
+			return x.Delete
+		}
+	}
+	return nil
+}
+
+func (x *IngestRequest) GetReaction() *AddReactionRequest {
+	if x != nil {
+		if x, ok := x.Op.(*IngestRequest_Reaction); ok {
+			return x.Reaction
+		}
+	}
+	return nil
+}
+
+func (x *IngestRequest) GetComment() *AddCommentRequest {
+	if x != nil {
+		if x, ok := x.Op.(*IngestRequest_Comment); ok {
+			return x.Comment
+		}
+	}
+	return nil
+}
+
+type isIngestRequest_Op interface {
+	isIngestRequest_Op()
+}
+
+type IngestRequest_Create struct {
+	Create *CreateTweetRequest `protobuf:"bytes,2,opt,name=create,proto3,oneof"`
+}
+
+type IngestRequest_Update struct {
+	Update *UpdateTweetRequest `protobuf:"bytes,3,opt,name=update,proto3,oneof"`
+}
+
+type IngestRequest_Delete struct {
+	Delete *DeleteTweetRequest `protobuf:"bytes,4,opt,name=delete,proto3,oneof"`
+}
+
+type IngestRequest_Reaction struct {
+	Reaction *AddReactionRequest `protobuf:"bytes,5,opt,name=reaction,proto3,oneof"`
+}
+
+type IngestRequest_Comment struct {
+	Comment *AddCommentRequest `protobuf:"bytes,6,opt,name=comment,proto3,oneof"`
+}
+
+func (*IngestRequest_Create) isIngestRequest_Op() {}
+
+func (*IngestRequest_Update) isIngestRequest_Op() {}
+
+func (*IngestRequest_Delete) isIngestRequest_Op() {}
+
+func (*IngestRequest_Reaction) isIngestRequest_Op() {}
+
+func (*IngestRequest_Comment) isIngestRequest_Op() {}
+
+// IngestAck answers one IngestRequest, in the order the requests were sent.
+// code is a gRPC status code, 0 when the write was applied.
+type IngestAck struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	IdempotencyKey string                 `protobuf:"bytes,1,opt,name=idempotency_key,json=idempotencyKey,proto3" json:"idempotency_key,omitempty"`
+	TweetId        string                 `protobuf:"bytes,2,opt,name=tweet_id,json=tweetId,proto3" json:"tweet_id,omitempty"`
+	Code           int32                  `protobuf:"varint,3,opt,name=code,proto3" json:"code,omitempty"`
+	Error          string                 `protobuf:"bytes,4,opt,name=error,proto3" json:"error,omitempty"`
+	Duplicate      bool                   `protobuf:"varint,5,opt,name=duplicate,proto3" json:"duplicate,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *IngestAck) Reset() {
+	*x = IngestAck{}
+	mi := &file_service_tweet_stream_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *IngestAck) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*IngestAck) ProtoMessage() {}
+
+func (x *IngestAck) ProtoReflect() protoreflect.Message {
+	mi := &file_service_tweet_stream_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use IngestAck.ProtoReflect.Descriptor instead.
+func (*IngestAck) Descriptor() ([]byte, []int) {
+	return file_service_tweet_stream_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *IngestAck) GetIdempotencyKey() string {
+	if x != nil {
+		return x.IdempotencyKey
+	}
+	return ""
+}
+
+func (x *IngestAck) GetTweetId() string {
+	if x != nil {
+		return x.TweetId
+	}
+	return ""
+}
+
+func (x *IngestAck) GetCode() int32 {
+	if x != nil {
+		return x.Code
+	}
+	return 0
+}
+
+func (x *IngestAck) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *IngestAck) GetDuplicate() bool {
+	if x != nil {
+		return x.Duplicate
+	}
+	return false
+}
+
 var File_service_tweet_stream_proto protoreflect.FileDescriptor
 
 const file_service_tweet_stream_proto_rawDesc = "" +
 	"\n" +
-	"\x1aservice_tweet_stream.proto\x12\x04grpc\x1a\vtweet.proto\"\a\n" +
-	"\x05Empty2;\n" +
-	"\fTweetService\x12+\n" +
-	"\fStreamTweets\x12\v.grpc.Empty\x1a\f.tweet.Tweet0\x01B$Z\"github.com/Udehlee/tweet-stream/pbb\x06proto3"
+	"\x1aservice_tweet_stream.proto\x12\x04grpc\x1a\vtweet.proto\x1a\x11tweet_event.proto\x1a\n" +
+	"user.proto\x1a\x1fgoogle/protobuf/timestamp.proto\"\a\n" +
+	"\x05Empty\"\xa9\x02\n" +
+	"\rStreamRequest\x12\x1a\n" +
+	"\bhashtags\x18\x01 \x03(\tR\bhashtags\x12\x19\n" +
+	"\buser_ids\x18\x02 \x03(\tR\auserIds\x12#\n" +
+	"\rverified_only\x18\x03 \x01(\bR\fverifiedOnly\x12\x1a\n" +
+	"\bkeywords\x18\x04 \x03(\tR\bkeywords\x12#\n" +
+	"\rmessage_regex\x18\x05 \x01(\tR\fmessageRegex\x121\n" +
+	"\vevent_types\x18\x06 \x03(\x0e2\x10.event.EventTypeR\n" +
+	"eventTypes\x121\n" +
+	"\x12resume_from_offset\x18\a \x01(\x04H\x00R\x10resumeFromOffset\x88\x01\x01B\x15\n" +
+	"\x13_resume_from_offset\")\n" +
+	"\x0eGetUserRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\"N\n" +
+	"\x10ListUsersRequest\x12\x1b\n" +
+	"\tpage_size\x18\x01 \x01(\x05R\bpageSize\x12\x1d\n" +
+	"\n" +
+	"page_token\x18\x02 \x01(\tR\tpageToken\"k\n" +
+	"\x14ListFollowersRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x1b\n" +
+	"\tpage_size\x18\x02 \x01(\x05R\bpageSize\x12\x1d\n" +
+	"\n" +
+	"page_token\x18\x03 \x01(\tR\tpageToken\"k\n" +
+	"\x14ListFollowingRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x1b\n" +
+	"\tpage_size\x18\x02 \x01(\x05R\bpageSize\x12\x1d\n" +
+	"\n" +
+	"page_token\x18\x03 \x01(\tR\tpageToken\"]\n" +
+	"\x11ListUsersResponse\x12 \n" +
+	"\x05users\x18\x01 \x03(\v2\n" +
+	".user.UserR\x05users\x12&\n" +
+	"\x0fnext_page_token\x18\x02 \x01(\tR\rnextPageToken\"!\n" +
+	"\x0fGetTweetRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"\x86\x02\n" +
+	"\x11ListTweetsRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x18\n" +
+	"\ahashtag\x18\x02 \x01(\tR\ahashtag\x12?\n" +
+	"\rcreated_after\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\fcreatedAfter\x12A\n" +
+	"\x0ecreated_before\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\rcreatedBefore\x12\x1b\n" +
+	"\tpage_size\x18\x05 \x01(\x05R\bpageSize\x12\x1d\n" +
+	"\n" +
+	"page_token\x18\x06 \x01(\tR\tpageToken\"b\n" +
+	"\x12ListTweetsResponse\x12$\n" +
+	"\x06tweets\x18\x01 \x03(\v2\f.tweet.TweetR\x06tweets\x12&\n" +
+	"\x0fnext_page_token\x18\x02 \x01(\tR\rnextPageToken\"\xcb\x01\n" +
+	"\x12CountTweetsRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x18\n" +
+	"\ahashtag\x18\x02 \x01(\tR\ahashtag\x12?\n" +
+	"\rcreated_after\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\fcreatedAfter\x12A\n" +
+	"\x0ecreated_before\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\rcreatedBefore\"+\n" +
+	"\x13CountTweetsResponse\x12\x14\n" +
+	"\x05count\x18\x01 \x01(\x03R\x05count\"G\n" +
+	"\x12CreateTweetRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\">\n" +
+	"\x12UpdateTweetRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\"$\n" +
+	"\x12DeleteTweetRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"m\n" +
+	"\x12AddReactionRequest\x12\x19\n" +
+	"\btweet_id\x18\x01 \x01(\tR\atweetId\x12\x17\n" +
+	"\auser_id\x18\x02 \x01(\tR\x06userId\x12#\n" +
+	"\rreaction_type\x18\x03 \x01(\tR\freactionType\"a\n" +
+	"\x11AddCommentRequest\x12\x19\n" +
+	"\btweet_id\x18\x01 \x01(\tR\atweetId\x12\x17\n" +
+	"\auser_id\x18\x02 \x01(\tR\x06userId\x12\x18\n" +
+	"\acontent\x18\x03 \x01(\tR\acontent\"\xc7\x02\n" +
+	"\rIngestRequest\x12'\n" +
+	"\x0fidempotency_key\x18\x01 \x01(\tR\x0eidempotencyKey\x122\n" +
+	"\x06create\x18\x02 \x01(\v2\x18.grpc.CreateTweetRequestH\x00R\x06create\x122\n" +
+	"\x06update\x18\x03 \x01(\v2\x18.grpc.UpdateTweetRequestH\x00R\x06update\x122\n" +
+	"\x06delete\x18\x04 \x01(\v2\x18.grpc.DeleteTweetRequestH\x00R\x06delete\x126\n" +
+	"\breaction\x18\x05 \x01(\v2\x18.grpc.AddReactionRequestH\x00R\breaction\x123\n" +
+	"\acomment\x18\x06 \x01(\v2\x17.grpc.AddCommentRequestH\x00R\acommentB\x04\n" +
+	"\x02op\"\x97\x01\n" +
+	"\tIngestAck\x12'\n" +
+	"\x0fidempotency_key\x18\x01 \x01(\tR\x0eidempotencyKey\x12\x19\n" +
+	"\btweet_id\x18\x02 \x01(\tR\atweetId\x12\x12\n" +
+	"\x04code\x18\x03 \x01(\x05R\x04code\x12\x14\n" +
+	"\x05error\x18\x04 \x01(\tR\x05error\x12\x1c\n" +
+	"\tduplicate\x18\x05 \x01(\bR\tduplicate2\xc0\x06\n" +
+	"\fTweetService\x128\n" +
+	"\fStreamTweets\x12\x13.grpc.StreamRequest\x1a\x11.event.TweetEvent0\x01\x12+\n" +
+	"\aGetUser\x12\x14.grpc.GetUserRequest\x1a\n" +
+	".user.User\x12<\n" +
+	"\tListUsers\x12\x16.grpc.ListUsersRequest\x1a\x17.grpc.ListUsersResponse\x12D\n" +
+	"\rListFollowers\x12\x1a.grpc.ListFollowersRequest\x1a\x17.grpc.ListUsersResponse\x12D\n" +
+	"\rListFollowing\x12\x1a.grpc.ListFollowingRequest\x1a\x17.grpc.ListUsersResponse\x12/\n" +
+	"\bGetTweet\x12\x15.grpc.GetTweetRequest\x1a\f.tweet.Tweet\x12?\n" +
+	"\n" +
+	"ListTweets\x12\x17.grpc.ListTweetsRequest\x1a\x18.grpc.ListTweetsResponse\x12B\n" +
+	"\vCountTweets\x12\x18.grpc.CountTweetsRequest\x1a\x19.grpc.CountTweetsResponse\x125\n" +
+	"\vCreateTweet\x12\x18.grpc.CreateTweetRequest\x1a\f.tweet.Tweet\x125\n" +
+	"\vUpdateTweet\x12\x18.grpc.UpdateTweetRequest\x1a\f.tweet.Tweet\x125\n" +
+	"\vDeleteTweet\x12\x18.grpc.DeleteTweetRequest\x1a\f.tweet.Tweet\x125\n" +
+	"\vAddReaction\x12\x18.grpc.AddReactionRequest\x1a\f.tweet.Tweet\x123\n" +
+	"\n" +
+	"AddComment\x12\x17.grpc.AddCommentRequest\x1a\f.tweet.Tweet\x128\n" +
+	"\fIngestTweets\x12\x13.grpc.IngestRequest\x1a\x0f.grpc.IngestAck(\x010\x01B$Z\"github.com/Udehlee/tweet-stream/pbb\x06proto3"
 
 var (
 	file_service_tweet_stream_proto_rawDescOnce sync.Once
@@ -78,19 +1326,79 @@ func file_service_tweet_stream_proto_rawDescGZIP() []byte {
 	return file_service_tweet_stream_proto_rawDescData
 }
 
-var file_service_tweet_stream_proto_msgTypes = make([]protoimpl.MessageInfo, 1)
+var file_service_tweet_stream_proto_msgTypes = make([]protoimpl.MessageInfo, 19)
 var file_service_tweet_stream_proto_goTypes = []any{
-	(*Empty)(nil), // 0: grpc.Empty
-	(*Tweet)(nil), // 1: tweet.Tweet
+	(*Empty)(nil),                 // 0: grpc.Empty
+	(*StreamRequest)(nil),         // 1: grpc.StreamRequest
+	(*GetUserRequest)(nil),        // 2: grpc.GetUserRequest
+	(*ListUsersRequest)(nil),      // 3: grpc.ListUsersRequest
+	(*ListFollowersRequest)(nil),  // 4: grpc.ListFollowersRequest
+	(*ListFollowingRequest)(nil),  // 5: grpc.ListFollowingRequest
+	(*ListUsersResponse)(nil),     // 6: grpc.ListUsersResponse
+	(*GetTweetRequest)(nil),       // 7: grpc.GetTweetRequest
+	(*ListTweetsRequest)(nil),     // 8: grpc.ListTweetsRequest
+	(*ListTweetsResponse)(nil),    // 9: grpc.ListTweetsResponse
+	(*CountTweetsRequest)(nil),    // 10: grpc.CountTweetsRequest
+	(*CountTweetsResponse)(nil),   // 11: grpc.CountTweetsResponse
+	(*CreateTweetRequest)(nil),    // 12: grpc.CreateTweetRequest
+	(*UpdateTweetRequest)(nil),    // 13: grpc.UpdateTweetRequest
+	(*DeleteTweetRequest)(nil),    // 14: grpc.DeleteTweetRequest
+	(*AddReactionRequest)(nil),    // 15: grpc.AddReactionRequest
+	(*AddCommentRequest)(nil),     // 16: grpc.AddCommentRequest
+	(*IngestRequest)(nil),         // 17: grpc.IngestRequest
+	(*IngestAck)(nil),             // 18: grpc.IngestAck
+	(EventType)(0),                // 19: event.EventType
+	(*User)(nil),                  // 20: user.User
+	(*timestamppb.Timestamp)(nil), // 21: google.protobuf.Timestamp
+	(*Tweet)(nil),                 // 22: tweet.Tweet
+	(*TweetEvent)(nil),            // 23: event.TweetEvent
 }
 var file_service_tweet_stream_proto_depIdxs = []int32{
-	0, // 0: grpc.TweetService.StreamTweets:input_type -> grpc.Empty
-	1, // 1: grpc.TweetService.StreamTweets:output_type -> tweet.Tweet
-	1, // [1:2] is the sub-list for method output_type
-	0, // [0:1] is the sub-list for method input_type
-	0, // [0:0] is the sub-list for extension type_name
-	0, // [0:0] is the sub-list for extension extendee
-	0, // [0:0] is the sub-list for field type_name
+	19, // 0: grpc.StreamRequest.event_types:type_name -> event.EventType
+	20, // 1: grpc.ListUsersResponse.users:type_name -> user.User
+	21, // 2: grpc.ListTweetsRequest.created_after:type_name -> google.protobuf.Timestamp
+	21, // 3: grpc.ListTweetsRequest.created_before:type_name -> google.protobuf.Timestamp
+	22, // 4: grpc.ListTweetsResponse.tweets:type_name -> tweet.Tweet
+	21, // 5: grpc.CountTweetsRequest.created_after:type_name -> google.protobuf.Timestamp
+	21, // 6: grpc.CountTweetsRequest.created_before:type_name -> google.protobuf.Timestamp
+	12, // 7: grpc.IngestRequest.create:type_name -> grpc.CreateTweetRequest
+	13, // 8: grpc.IngestRequest.update:type_name -> grpc.UpdateTweetRequest
+	14, // 9: grpc.IngestRequest.delete:type_name -> grpc.DeleteTweetRequest
+	15, // 10: grpc.IngestRequest.reaction:type_name -> grpc.AddReactionRequest
+	16, // 11: grpc.IngestRequest.comment:type_name -> grpc.AddCommentRequest
+	1,  // 12: grpc.TweetService.StreamTweets:input_type -> grpc.StreamRequest
+	2,  // 13: grpc.TweetService.GetUser:input_type -> grpc.GetUserRequest
+	3,  // 14: grpc.TweetService.ListUsers:input_type -> grpc.ListUsersRequest
+	4,  // 15: grpc.TweetService.ListFollowers:input_type -> grpc.ListFollowersRequest
+	5,  // 16: grpc.TweetService.ListFollowing:input_type -> grpc.ListFollowingRequest
+	7,  // 17: grpc.TweetService.GetTweet:input_type -> grpc.GetTweetRequest
+	8,  // 18: grpc.TweetService.ListTweets:input_type -> grpc.ListTweetsRequest
+	10, // 19: grpc.TweetService.CountTweets:input_type -> grpc.CountTweetsRequest
+	12, // 20: grpc.TweetService.CreateTweet:input_type -> grpc.CreateTweetRequest
+	13, // 21: grpc.TweetService.UpdateTweet:input_type -> grpc.UpdateTweetRequest
+	14, // 22: grpc.TweetService.DeleteTweet:input_type -> grpc.DeleteTweetRequest
+	15, // 23: grpc.TweetService.AddReaction:input_type -> grpc.AddReactionRequest
+	16, // 24: grpc.TweetService.AddComment:input_type -> grpc.AddCommentRequest
+	17, // 25: grpc.TweetService.IngestTweets:input_type -> grpc.IngestRequest
+	23, // 26: grpc.TweetService.StreamTweets:output_type -> event.TweetEvent
+	20, // 27: grpc.TweetService.GetUser:output_type -> user.User
+	6,  // 28: grpc.TweetService.ListUsers:output_type -> grpc.ListUsersResponse
+	6,  // 29: grpc.TweetService.ListFollowers:output_type -> grpc.ListUsersResponse
+	6,  // 30: grpc.TweetService.ListFollowing:output_type -> grpc.ListUsersResponse
+	22, // 31: grpc.TweetService.GetTweet:output_type -> tweet.Tweet
+	9,  // 32: grpc.TweetService.ListTweets:output_type -> grpc.ListTweetsResponse
+	11, // 33: grpc.TweetService.CountTweets:output_type -> grpc.CountTweetsResponse
+	22, // 34: grpc.TweetService.CreateTweet:output_type -> tweet.Tweet
+	22, // 35: grpc.TweetService.UpdateTweet:output_type -> tweet.Tweet
+	22, // 36: grpc.TweetService.DeleteTweet:output_type -> tweet.Tweet
+	22, // 37: grpc.TweetService.AddReaction:output_type -> tweet.Tweet
+	22, // 38: grpc.TweetService.AddComment:output_type -> tweet.Tweet
+	18, // 39: grpc.TweetService.IngestTweets:output_type -> grpc.IngestAck
+	26, // [26:40] is the sub-list for method output_type
+	12, // [12:26] is the sub-list for method input_type
+	12, // [12:12] is the sub-list for extension type_name
+	12, // [12:12] is the sub-list for extension extendee
+	0,  // [0:12] is the sub-list for field type_name
 }
 
 func init() { file_service_tweet_stream_proto_init() }
@@ -99,13 +1407,23 @@ func file_service_tweet_stream_proto_init() {
 		return
 	}
 	file_tweet_proto_init()
+	file_tweet_event_proto_init()
+	file_user_proto_init()
+	file_service_tweet_stream_proto_msgTypes[1].OneofWrappers = []any{}
+	file_service_tweet_stream_proto_msgTypes[17].OneofWrappers = []any{
+		(*IngestRequest_Create)(nil),
+		(*IngestRequest_Update)(nil),
+		(*IngestRequest_Delete)(nil),
+		(*IngestRequest_Reaction)(nil),
+		(*IngestRequest_Comment)(nil),
+	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_service_tweet_stream_proto_rawDesc), len(file_service_tweet_stream_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   1,
+			NumMessages:   19,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
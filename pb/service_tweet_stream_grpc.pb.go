@@ -19,14 +19,45 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	TweetService_StreamTweets_FullMethodName = "/grpc.TweetService/StreamTweets"
+	TweetService_StreamTweets_FullMethodName  = "/grpc.TweetService/StreamTweets"
+	TweetService_GetUser_FullMethodName       = "/grpc.TweetService/GetUser"
+	TweetService_ListUsers_FullMethodName     = "/grpc.TweetService/ListUsers"
+	TweetService_ListFollowers_FullMethodName = "/grpc.TweetService/ListFollowers"
+	TweetService_ListFollowing_FullMethodName = "/grpc.TweetService/ListFollowing"
+	TweetService_GetTweet_FullMethodName      = "/grpc.TweetService/GetTweet"
+	TweetService_ListTweets_FullMethodName    = "/grpc.TweetService/ListTweets"
+	TweetService_CountTweets_FullMethodName   = "/grpc.TweetService/CountTweets"
+	TweetService_CreateTweet_FullMethodName   = "/grpc.TweetService/CreateTweet"
+	TweetService_UpdateTweet_FullMethodName   = "/grpc.TweetService/UpdateTweet"
+	TweetService_DeleteTweet_FullMethodName   = "/grpc.TweetService/DeleteTweet"
+	TweetService_AddReaction_FullMethodName   = "/grpc.TweetService/AddReaction"
+	TweetService_AddComment_FullMethodName    = "/grpc.TweetService/AddComment"
+	TweetService_IngestTweets_FullMethodName  = "/grpc.TweetService/IngestTweets"
 )
 
 // TweetServiceClient is the client API for TweetService service.
 //
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
 type TweetServiceClient interface {
-	StreamTweets(ctx context.Context, in *Empty, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Tweet], error)
+	StreamTweets(ctx context.Context, in *StreamRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[TweetEvent], error)
+	GetUser(ctx context.Context, in *GetUserRequest, opts ...grpc.CallOption) (*User, error)
+	ListUsers(ctx context.Context, in *ListUsersRequest, opts ...grpc.CallOption) (*ListUsersResponse, error)
+	ListFollowers(ctx context.Context, in *ListFollowersRequest, opts ...grpc.CallOption) (*ListUsersResponse, error)
+	ListFollowing(ctx context.Context, in *ListFollowingRequest, opts ...grpc.CallOption) (*ListUsersResponse, error)
+	GetTweet(ctx context.Context, in *GetTweetRequest, opts ...grpc.CallOption) (*Tweet, error)
+	ListTweets(ctx context.Context, in *ListTweetsRequest, opts ...grpc.CallOption) (*ListTweetsResponse, error)
+	CountTweets(ctx context.Context, in *CountTweetsRequest, opts ...grpc.CallOption) (*CountTweetsResponse, error)
+	// Write RPCs return the stored tweet, DeleteTweet its last version,
+	// and publish the same event on the stream as generated changes.
+	CreateTweet(ctx context.Context, in *CreateTweetRequest, opts ...grpc.CallOption) (*Tweet, error)
+	UpdateTweet(ctx context.Context, in *UpdateTweetRequest, opts ...grpc.CallOption) (*Tweet, error)
+	DeleteTweet(ctx context.Context, in *DeleteTweetRequest, opts ...grpc.CallOption) (*Tweet, error)
+	AddReaction(ctx context.Context, in *AddReactionRequest, opts ...grpc.CallOption) (*Tweet, error)
+	AddComment(ctx context.Context, in *AddCommentRequest, opts ...grpc.CallOption) (*Tweet, error)
+	// IngestTweets applies a stream of writes and acks each of them. While the
+	// pipeline is saturated the server stops reading, so producers are pushed
+	// back by flow control instead of having their events dropped.
+	IngestTweets(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[IngestRequest, IngestAck], error)
 }
 
 type tweetServiceClient struct {
@@ -37,13 +68,13 @@ func NewTweetServiceClient(cc grpc.ClientConnInterface) TweetServiceClient {
 	return &tweetServiceClient{cc}
 }
 
-func (c *tweetServiceClient) StreamTweets(ctx context.Context, in *Empty, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Tweet], error) {
+func (c *tweetServiceClient) StreamTweets(ctx context.Context, in *StreamRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[TweetEvent], error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	stream, err := c.cc.NewStream(ctx, &TweetService_ServiceDesc.Streams[0], TweetService_StreamTweets_FullMethodName, cOpts...)
 	if err != nil {
 		return nil, err
 	}
-	x := &grpc.GenericClientStream[Empty, Tweet]{ClientStream: stream}
+	x := &grpc.GenericClientStream[StreamRequest, TweetEvent]{ClientStream: stream}
 	if err := x.ClientStream.SendMsg(in); err != nil {
 		return nil, err
 	}
@@ -54,13 +85,164 @@ func (c *tweetServiceClient) StreamTweets(ctx context.Context, in *Empty, opts .
 }
 
 // This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
-type TweetService_StreamTweetsClient = grpc.ServerStreamingClient[Tweet]
+type TweetService_StreamTweetsClient = grpc.ServerStreamingClient[TweetEvent]
+
+func (c *tweetServiceClient) GetUser(ctx context.Context, in *GetUserRequest, opts ...grpc.CallOption) (*User, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(User)
+	err := c.cc.Invoke(ctx, TweetService_GetUser_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *tweetServiceClient) ListUsers(ctx context.Context, in *ListUsersRequest, opts ...grpc.CallOption) (*ListUsersResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListUsersResponse)
+	err := c.cc.Invoke(ctx, TweetService_ListUsers_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *tweetServiceClient) ListFollowers(ctx context.Context, in *ListFollowersRequest, opts ...grpc.CallOption) (*ListUsersResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListUsersResponse)
+	err := c.cc.Invoke(ctx, TweetService_ListFollowers_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *tweetServiceClient) ListFollowing(ctx context.Context, in *ListFollowingRequest, opts ...grpc.CallOption) (*ListUsersResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListUsersResponse)
+	err := c.cc.Invoke(ctx, TweetService_ListFollowing_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *tweetServiceClient) GetTweet(ctx context.Context, in *GetTweetRequest, opts ...grpc.CallOption) (*Tweet, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Tweet)
+	err := c.cc.Invoke(ctx, TweetService_GetTweet_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *tweetServiceClient) ListTweets(ctx context.Context, in *ListTweetsRequest, opts ...grpc.CallOption) (*ListTweetsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListTweetsResponse)
+	err := c.cc.Invoke(ctx, TweetService_ListTweets_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *tweetServiceClient) CountTweets(ctx context.Context, in *CountTweetsRequest, opts ...grpc.CallOption) (*CountTweetsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CountTweetsResponse)
+	err := c.cc.Invoke(ctx, TweetService_CountTweets_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *tweetServiceClient) CreateTweet(ctx context.Context, in *CreateTweetRequest, opts ...grpc.CallOption) (*Tweet, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Tweet)
+	err := c.cc.Invoke(ctx, TweetService_CreateTweet_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *tweetServiceClient) UpdateTweet(ctx context.Context, in *UpdateTweetRequest, opts ...grpc.CallOption) (*Tweet, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Tweet)
+	err := c.cc.Invoke(ctx, TweetService_UpdateTweet_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *tweetServiceClient) DeleteTweet(ctx context.Context, in *DeleteTweetRequest, opts ...grpc.CallOption) (*Tweet, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Tweet)
+	err := c.cc.Invoke(ctx, TweetService_DeleteTweet_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *tweetServiceClient) AddReaction(ctx context.Context, in *AddReactionRequest, opts ...grpc.CallOption) (*Tweet, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Tweet)
+	err := c.cc.Invoke(ctx, TweetService_AddReaction_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *tweetServiceClient) AddComment(ctx context.Context, in *AddCommentRequest, opts ...grpc.CallOption) (*Tweet, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Tweet)
+	err := c.cc.Invoke(ctx, TweetService_AddComment_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *tweetServiceClient) IngestTweets(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[IngestRequest, IngestAck], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &TweetService_ServiceDesc.Streams[1], TweetService_IngestTweets_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[IngestRequest, IngestAck]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type TweetService_IngestTweetsClient = grpc.BidiStreamingClient[IngestRequest, IngestAck]
 
 // TweetServiceServer is the server API for TweetService service.
 // All implementations must embed UnimplementedTweetServiceServer
 // for forward compatibility.
 type TweetServiceServer interface {
-	StreamTweets(*Empty, grpc.ServerStreamingServer[Tweet]) error
+	StreamTweets(*StreamRequest, grpc.ServerStreamingServer[TweetEvent]) error
+	GetUser(context.Context, *GetUserRequest) (*User, error)
+	ListUsers(context.Context, *ListUsersRequest) (*ListUsersResponse, error)
+	ListFollowers(context.Context, *ListFollowersRequest) (*ListUsersResponse, error)
+	ListFollowing(context.Context, *ListFollowingRequest) (*ListUsersResponse, error)
+	GetTweet(context.Context, *GetTweetRequest) (*Tweet, error)
+	ListTweets(context.Context, *ListTweetsRequest) (*ListTweetsResponse, error)
+	CountTweets(context.Context, *CountTweetsRequest) (*CountTweetsResponse, error)
+	// Write RPCs return the stored tweet, DeleteTweet its last version,
+	// and publish the same event on the stream as generated changes.
+	CreateTweet(context.Context, *CreateTweetRequest) (*Tweet, error)
+	UpdateTweet(context.Context, *UpdateTweetRequest) (*Tweet, error)
+	DeleteTweet(context.Context, *DeleteTweetRequest) (*Tweet, error)
+	AddReaction(context.Context, *AddReactionRequest) (*Tweet, error)
+	AddComment(context.Context, *AddCommentRequest) (*Tweet, error)
+	// IngestTweets applies a stream of writes and acks each of them. While the
+	// pipeline is saturated the server stops reading, so producers are pushed
+	// back by flow control instead of having their events dropped.
+	IngestTweets(grpc.BidiStreamingServer[IngestRequest, IngestAck]) error
 	mustEmbedUnimplementedTweetServiceServer()
 }
 
@@ -71,9 +253,48 @@ type TweetServiceServer interface {
 // pointer dereference when methods are called.
 type UnimplementedTweetServiceServer struct{}
 
-func (UnimplementedTweetServiceServer) StreamTweets(*Empty, grpc.ServerStreamingServer[Tweet]) error {
+func (UnimplementedTweetServiceServer) StreamTweets(*StreamRequest, grpc.ServerStreamingServer[TweetEvent]) error {
 	return status.Errorf(codes.Unimplemented, "method StreamTweets not implemented")
 }
+func (UnimplementedTweetServiceServer) GetUser(context.Context, *GetUserRequest) (*User, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetUser not implemented")
+}
+func (UnimplementedTweetServiceServer) ListUsers(context.Context, *ListUsersRequest) (*ListUsersResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListUsers not implemented")
+}
+func (UnimplementedTweetServiceServer) ListFollowers(context.Context, *ListFollowersRequest) (*ListUsersResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListFollowers not implemented")
+}
+func (UnimplementedTweetServiceServer) ListFollowing(context.Context, *ListFollowingRequest) (*ListUsersResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListFollowing not implemented")
+}
+func (UnimplementedTweetServiceServer) GetTweet(context.Context, *GetTweetRequest) (*Tweet, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetTweet not implemented")
+}
+func (UnimplementedTweetServiceServer) ListTweets(context.Context, *ListTweetsRequest) (*ListTweetsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListTweets not implemented")
+}
+func (UnimplementedTweetServiceServer) CountTweets(context.Context, *CountTweetsRequest) (*CountTweetsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CountTweets not implemented")
+}
+func (UnimplementedTweetServiceServer) CreateTweet(context.Context, *CreateTweetRequest) (*Tweet, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateTweet not implemented")
+}
+func (UnimplementedTweetServiceServer) UpdateTweet(context.Context, *UpdateTweetRequest) (*Tweet, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateTweet not implemented")
+}
+func (UnimplementedTweetServiceServer) DeleteTweet(context.Context, *DeleteTweetRequest) (*Tweet, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteTweet not implemented")
+}
+func (UnimplementedTweetServiceServer) AddReaction(context.Context, *AddReactionRequest) (*Tweet, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AddReaction not implemented")
+}
+func (UnimplementedTweetServiceServer) AddComment(context.Context, *AddCommentRequest) (*Tweet, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AddComment not implemented")
+}
+func (UnimplementedTweetServiceServer) IngestTweets(grpc.BidiStreamingServer[IngestRequest, IngestAck]) error {
+	return status.Errorf(codes.Unimplemented, "method IngestTweets not implemented")
+}
 func (UnimplementedTweetServiceServer) mustEmbedUnimplementedTweetServiceServer() {}
 func (UnimplementedTweetServiceServer) testEmbeddedByValue()                      {}
 
@@ -96,15 +317,238 @@ func RegisterTweetServiceServer(s grpc.ServiceRegistrar, srv TweetServiceServer)
 }
 
 func _TweetService_StreamTweets_Handler(srv interface{}, stream grpc.ServerStream) error {
-	m := new(Empty)
+	m := new(StreamRequest)
 	if err := stream.RecvMsg(m); err != nil {
 		return err
 	}
-	return srv.(TweetServiceServer).StreamTweets(m, &grpc.GenericServerStream[Empty, Tweet]{ServerStream: stream})
+	return srv.(TweetServiceServer).StreamTweets(m, &grpc.GenericServerStream[StreamRequest, TweetEvent]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type TweetService_StreamTweetsServer = grpc.ServerStreamingServer[TweetEvent]
+
+func _TweetService_GetUser_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetUserRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TweetServiceServer).GetUser(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TweetService_GetUser_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TweetServiceServer).GetUser(ctx, req.(*GetUserRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TweetService_ListUsers_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListUsersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TweetServiceServer).ListUsers(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TweetService_ListUsers_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TweetServiceServer).ListUsers(ctx, req.(*ListUsersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TweetService_ListFollowers_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListFollowersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TweetServiceServer).ListFollowers(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TweetService_ListFollowers_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TweetServiceServer).ListFollowers(ctx, req.(*ListFollowersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TweetService_ListFollowing_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListFollowingRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TweetServiceServer).ListFollowing(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TweetService_ListFollowing_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TweetServiceServer).ListFollowing(ctx, req.(*ListFollowingRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TweetService_GetTweet_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetTweetRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TweetServiceServer).GetTweet(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TweetService_GetTweet_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TweetServiceServer).GetTweet(ctx, req.(*GetTweetRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TweetService_ListTweets_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListTweetsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TweetServiceServer).ListTweets(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TweetService_ListTweets_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TweetServiceServer).ListTweets(ctx, req.(*ListTweetsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TweetService_CountTweets_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CountTweetsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TweetServiceServer).CountTweets(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TweetService_CountTweets_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TweetServiceServer).CountTweets(ctx, req.(*CountTweetsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TweetService_CreateTweet_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateTweetRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TweetServiceServer).CreateTweet(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TweetService_CreateTweet_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TweetServiceServer).CreateTweet(ctx, req.(*CreateTweetRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TweetService_UpdateTweet_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateTweetRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TweetServiceServer).UpdateTweet(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TweetService_UpdateTweet_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TweetServiceServer).UpdateTweet(ctx, req.(*UpdateTweetRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TweetService_DeleteTweet_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteTweetRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TweetServiceServer).DeleteTweet(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TweetService_DeleteTweet_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TweetServiceServer).DeleteTweet(ctx, req.(*DeleteTweetRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TweetService_AddReaction_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AddReactionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TweetServiceServer).AddReaction(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TweetService_AddReaction_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TweetServiceServer).AddReaction(ctx, req.(*AddReactionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TweetService_AddComment_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AddCommentRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TweetServiceServer).AddComment(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TweetService_AddComment_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TweetServiceServer).AddComment(ctx, req.(*AddCommentRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TweetService_IngestTweets_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(TweetServiceServer).IngestTweets(&grpc.GenericServerStream[IngestRequest, IngestAck]{ServerStream: stream})
 }
 
 // This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
-type TweetService_StreamTweetsServer = grpc.ServerStreamingServer[Tweet]
+type TweetService_IngestTweetsServer = grpc.BidiStreamingServer[IngestRequest, IngestAck]
 
 // TweetService_ServiceDesc is the grpc.ServiceDesc for TweetService service.
 // It's only intended for direct use with grpc.RegisterService,
@@ -112,13 +556,68 @@ type TweetService_StreamTweetsServer = grpc.ServerStreamingServer[Tweet]
 var TweetService_ServiceDesc = grpc.ServiceDesc{
 	ServiceName: "grpc.TweetService",
 	HandlerType: (*TweetServiceServer)(nil),
-	Methods:     []grpc.MethodDesc{},
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetUser",
+			Handler:    _TweetService_GetUser_Handler,
+		},
+		{
+			MethodName: "ListUsers",
+			Handler:    _TweetService_ListUsers_Handler,
+		},
+		{
+			MethodName: "ListFollowers",
+			Handler:    _TweetService_ListFollowers_Handler,
+		},
+		{
+			MethodName: "ListFollowing",
+			Handler:    _TweetService_ListFollowing_Handler,
+		},
+		{
+			MethodName: "GetTweet",
+			Handler:    _TweetService_GetTweet_Handler,
+		},
+		{
+			MethodName: "ListTweets",
+			Handler:    _TweetService_ListTweets_Handler,
+		},
+		{
+			MethodName: "CountTweets",
+			Handler:    _TweetService_CountTweets_Handler,
+		},
+		{
+			MethodName: "CreateTweet",
+			Handler:    _TweetService_CreateTweet_Handler,
+		},
+		{
+			MethodName: "UpdateTweet",
+			Handler:    _TweetService_UpdateTweet_Handler,
+		},
+		{
+			MethodName: "DeleteTweet",
+			Handler:    _TweetService_DeleteTweet_Handler,
+		},
+		{
+			MethodName: "AddReaction",
+			Handler:    _TweetService_AddReaction_Handler,
+		},
+		{
+			MethodName: "AddComment",
+			Handler:    _TweetService_AddComment_Handler,
+		},
+	},
 	Streams: []grpc.StreamDesc{
 		{
 			StreamName:    "StreamTweets",
 			Handler:       _TweetService_StreamTweets_Handler,
 			ServerStreams: true,
 		},
+		{
+			StreamName:    "IngestTweets",
+			Handler:       _TweetService_IngestTweets_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
 	},
 	Metadata: "service_tweet_stream.proto",
 }
@@ -32,6 +32,9 @@ type Tweet struct {
 	Comments      []*Comment             `protobuf:"bytes,6,rep,name=comments,proto3" json:"comments,omitempty"`
 	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
 	UpdatedAt     *timestamppb.Timestamp `protobuf:"bytes,8,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	Mentions      []string               `protobuf:"bytes,9,rep,name=mentions,proto3" json:"mentions,omitempty"`
+	Urls          []string               `protobuf:"bytes,10,rep,name=urls,proto3" json:"urls,omitempty"`
+	Cashtags      []string               `protobuf:"bytes,11,rep,name=cashtags,proto3" json:"cashtags,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -122,12 +125,33 @@ func (x *Tweet) GetUpdatedAt() *timestamppb.Timestamp {
 	return nil
 }
 
+func (x *Tweet) GetMentions() []string {
+	if x != nil {
+		return x.Mentions
+	}
+	return nil
+}
+
+func (x *Tweet) GetUrls() []string {
+	if x != nil {
+		return x.Urls
+	}
+	return nil
+}
+
+func (x *Tweet) GetCashtags() []string {
+	if x != nil {
+		return x.Cashtags
+	}
+	return nil
+}
+
 var File_tweet_proto protoreflect.FileDescriptor
 
 const file_tweet_proto_rawDesc = "" +
 	"\n" +
 	"\vtweet.proto\x12\x05tweet\x1a\n" +
-	"user.proto\x1a\rcomment.proto\x1a\x0ereaction.proto\x1a\x1fgoogle/protobuf/timestamp.proto\"\xc3\x02\n" +
+	"user.proto\x1a\rcomment.proto\x1a\x0ereaction.proto\x1a\x1fgoogle/protobuf/timestamp.proto\"\x8f\x03\n" +
 	"\x05Tweet\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\x12\x1e\n" +
 	"\x04user\x18\x02 \x01(\v2\n" +
@@ -139,7 +163,11 @@ const file_tweet_proto_rawDesc = "" +
 	"\n" +
 	"created_at\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x129\n" +
 	"\n" +
-	"updated_at\x18\b \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAtB$Z\"github.com/Udehlee/tweet-stream/pbb\x06proto3"
+	"updated_at\x18\b \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt\x12\x1a\n" +
+	"\bmentions\x18\t \x03(\tR\bmentions\x12\x12\n" +
+	"\x04urls\x18\n" +
+	" \x03(\tR\x04urls\x12\x1a\n" +
+	"\bcashtags\x18\v \x03(\tR\bcashtagsB$Z\"github.com/Udehlee/tweet-stream/pbb\x06proto3"
 
 var (
 	file_tweet_proto_rawDescOnce sync.Once
@@ -0,0 +1,246 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.10
+// 	protoc        v3.21.9
+// source: tweet_event.proto
+
+package pb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type EventType int32
+
+const (
+	EventType_EVENT_TYPE_UNSPECIFIED EventType = 0
+	EventType_EVENT_TYPE_CREATED     EventType = 1
+	EventType_EVENT_TYPE_UPDATED     EventType = 2
+	EventType_EVENT_TYPE_DELETED     EventType = 3
+)
+
+// Enum value maps for EventType.
+var (
+	EventType_name = map[int32]string{
+		0: "EVENT_TYPE_UNSPECIFIED",
+		1: "EVENT_TYPE_CREATED",
+		2: "EVENT_TYPE_UPDATED",
+		3: "EVENT_TYPE_DELETED",
+	}
+	EventType_value = map[string]int32{
+		"EVENT_TYPE_UNSPECIFIED": 0,
+		"EVENT_TYPE_CREATED":     1,
+		"EVENT_TYPE_UPDATED":     2,
+		"EVENT_TYPE_DELETED":     3,
+	}
+)
+
+func (x EventType) Enum() *EventType {
+	p := new(EventType)
+	*p = x
+	return p
+}
+
+func (x EventType) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (EventType) Descriptor() protoreflect.EnumDescriptor {
+	return file_tweet_event_proto_enumTypes[0].Descriptor()
+}
+
+func (EventType) Type() protoreflect.EnumType {
+	return &file_tweet_event_proto_enumTypes[0]
+}
+
+func (x EventType) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use EventType.Descriptor instead.
+func (EventType) EnumDescriptor() ([]byte, []int) {
+	return file_tweet_event_proto_rawDescGZIP(), []int{0}
+}
+
+type TweetEvent struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	EventId       string                 `protobuf:"bytes,1,opt,name=event_id,json=eventId,proto3" json:"event_id,omitempty"`
+	Type          EventType              `protobuf:"varint,2,opt,name=type,proto3,enum=event.EventType" json:"type,omitempty"`
+	Sequence      uint64                 `protobuf:"varint,3,opt,name=sequence,proto3" json:"sequence,omitempty"`
+	Tweet         *Tweet                 `protobuf:"bytes,4,opt,name=tweet,proto3" json:"tweet,omitempty"`
+	Previous      *Tweet                 `protobuf:"bytes,5,opt,name=previous,proto3" json:"previous,omitempty"`
+	EmittedAt     *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=emitted_at,json=emittedAt,proto3" json:"emitted_at,omitempty"`
+	Offset        uint64                 `protobuf:"varint,7,opt,name=offset,proto3" json:"offset,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TweetEvent) Reset() {
+	*x = TweetEvent{}
+	mi := &file_tweet_event_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TweetEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TweetEvent) ProtoMessage() {}
+
+func (x *TweetEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_tweet_event_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TweetEvent.ProtoReflect.Descriptor instead.
+func (*TweetEvent) Descriptor() ([]byte, []int) {
+	return file_tweet_event_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *TweetEvent) GetEventId() string {
+	if x != nil {
+		return x.EventId
+	}
+	return ""
+}
+
+func (x *TweetEvent) GetType() EventType {
+	if x != nil {
+		return x.Type
+	}
+	return EventType_EVENT_TYPE_UNSPECIFIED
+}
+
+func (x *TweetEvent) GetSequence() uint64 {
+	if x != nil {
+		return x.Sequence
+	}
+	return 0
+}
+
+func (x *TweetEvent) GetTweet() *Tweet {
+	if x != nil {
+		return x.Tweet
+	}
+	return nil
+}
+
+func (x *TweetEvent) GetPrevious() *Tweet {
+	if x != nil {
+		return x.Previous
+	}
+	return nil
+}
+
+func (x *TweetEvent) GetEmittedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.EmittedAt
+	}
+	return nil
+}
+
+func (x *TweetEvent) GetOffset() uint64 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+var File_tweet_event_proto protoreflect.FileDescriptor
+
+const file_tweet_event_proto_rawDesc = "" +
+	"\n" +
+	"\x11tweet_event.proto\x12\x05event\x1a\vtweet.proto\x1a\x1fgoogle/protobuf/timestamp.proto\"\x8a\x02\n" +
+	"\n" +
+	"TweetEvent\x12\x19\n" +
+	"\bevent_id\x18\x01 \x01(\tR\aeventId\x12$\n" +
+	"\x04type\x18\x02 \x01(\x0e2\x10.event.EventTypeR\x04type\x12\x1a\n" +
+	"\bsequence\x18\x03 \x01(\x04R\bsequence\x12\"\n" +
+	"\x05tweet\x18\x04 \x01(\v2\f.tweet.TweetR\x05tweet\x12(\n" +
+	"\bprevious\x18\x05 \x01(\v2\f.tweet.TweetR\bprevious\x129\n" +
+	"\n" +
+	"emitted_at\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\temittedAt\x12\x16\n" +
+	"\x06offset\x18\a \x01(\x04R\x06offset*o\n" +
+	"\tEventType\x12\x1a\n" +
+	"\x16EVENT_TYPE_UNSPECIFIED\x10\x00\x12\x16\n" +
+	"\x12EVENT_TYPE_CREATED\x10\x01\x12\x16\n" +
+	"\x12EVENT_TYPE_UPDATED\x10\x02\x12\x16\n" +
+	"\x12EVENT_TYPE_DELETED\x10\x03B$Z\"github.com/Udehlee/tweet-stream/pbb\x06proto3"
+
+var (
+	file_tweet_event_proto_rawDescOnce sync.Once
+	file_tweet_event_proto_rawDescData []byte
+)
+
+func file_tweet_event_proto_rawDescGZIP() []byte {
+	file_tweet_event_proto_rawDescOnce.Do(func() {
+		file_tweet_event_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_tweet_event_proto_rawDesc), len(file_tweet_event_proto_rawDesc)))
+	})
+	return file_tweet_event_proto_rawDescData
+}
+
+var file_tweet_event_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_tweet_event_proto_msgTypes = make([]protoimpl.MessageInfo, 1)
+var file_tweet_event_proto_goTypes = []any{
+	(EventType)(0),                // 0: event.EventType
+	(*TweetEvent)(nil),            // 1: event.TweetEvent
+	(*Tweet)(nil),                 // 2: tweet.Tweet
+	(*timestamppb.Timestamp)(nil), // 3: google.protobuf.Timestamp
+}
+var file_tweet_event_proto_depIdxs = []int32{
+	0, // 0: event.TweetEvent.type:type_name -> event.EventType
+	2, // 1: event.TweetEvent.tweet:type_name -> tweet.Tweet
+	2, // 2: event.TweetEvent.previous:type_name -> tweet.Tweet
+	3, // 3: event.TweetEvent.emitted_at:type_name -> google.protobuf.Timestamp
+	4, // [4:4] is the sub-list for method output_type
+	4, // [4:4] is the sub-list for method input_type
+	4, // [4:4] is the sub-list for extension type_name
+	4, // [4:4] is the sub-list for extension extendee
+	0, // [0:4] is the sub-list for field type_name
+}
+
+func init() { file_tweet_event_proto_init() }
+func file_tweet_event_proto_init() {
+	if File_tweet_event_proto != nil {
+		return
+	}
+	file_tweet_proto_init()
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_tweet_event_proto_rawDesc), len(file_tweet_event_proto_rawDesc)),
+			NumEnums:      1,
+			NumMessages:   1,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_tweet_event_proto_goTypes,
+		DependencyIndexes: file_tweet_event_proto_depIdxs,
+		EnumInfos:         file_tweet_event_proto_enumTypes,
+		MessageInfos:      file_tweet_event_proto_msgTypes,
+	}.Build()
+	File_tweet_event_proto = out.File
+	file_tweet_event_proto_goTypes = nil
+	file_tweet_event_proto_depIdxs = nil
+}
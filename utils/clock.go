@@ -0,0 +1,41 @@
+package utils
+
+import (
+	"sync"
+	"time"
+)
+
+// SeedEpoch is where the clock of a seeded simulation starts
+var SeedEpoch = time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// Clock tells the simulation what time it is
+type Clock interface {
+	Now() time.Time
+}
+
+// SystemClock reads the wall clock
+type SystemClock struct{}
+
+func (SystemClock) Now() time.Time {
+	return time.Now()
+}
+
+// StepClock starts at a fixed time and moves forward by step on every reading
+type StepClock struct {
+	mu   sync.Mutex
+	now  time.Time
+	step time.Duration
+}
+
+func NewStepClock(start time.Time, step time.Duration) *StepClock {
+	return &StepClock{now: start, step: step}
+}
+
+func (c *StepClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now
+	c.now = c.now.Add(c.step)
+	return now
+}
@@ -0,0 +1,106 @@
+package utils
+
+import (
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// Random is a goroutine safe random source
+// that can be seeded to make a simulation reproducible
+type Random struct {
+	mu sync.Mutex
+	r  *rand.Rand
+}
+
+// NewRandom returns a source seeded with seed
+func NewRandom(seed int64) *Random {
+	return &Random{r: rand.New(rand.NewSource(seed))}
+}
+
+// NewTimeRandom returns a source seeded from the current time
+func NewTimeRandom() *Random {
+	return NewRandom(time.Now().UnixNano())
+}
+
+func (r *Random) Intn(n int) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.r.Intn(n)
+}
+
+func (r *Random) Int63n(n int64) int64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.r.Int63n(n)
+}
+
+func (r *Random) Float64() float64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.r.Float64()
+}
+
+func (r *Random) ExpFloat64() float64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.r.ExpFloat64()
+}
+
+// IDGenerator hands out tweet, user and event ids
+type IDGenerator interface {
+	NewID() string
+}
+
+// UUIDGenerator generates ids from random uuids
+type UUIDGenerator struct{}
+
+func (UUIDGenerator) NewID() string {
+	return GenerateID()
+}
+
+// SeededIDGenerator generates ids in the same format as GenerateID
+// from a seeded source so the same seed yields the same ids
+type SeededIDGenerator struct {
+	Rand *Random
+}
+
+func (g SeededIDGenerator) NewID() string {
+	return fmt.Sprintf("%06X", g.Rand.Int63n(1<<24))
+}
+
+// Source bundles every source of nondeterminism in the simulation
+type Source struct {
+	Rand  *Random
+	IDs   IDGenerator
+	Clock Clock // tweet and user timestamps
+
+	// Events stamps event envelopes, it follows real time even in a seeded
+	// run so latency and emit time windows measure the run itself
+	Events Clock
+}
+
+// NewSource uses the wall clock, uuids and a time seeded random source
+func NewSource() *Source {
+	return &Source{
+		Rand:   NewTimeRandom(),
+		IDs:    UUIDGenerator{},
+		Clock:  SystemClock{},
+		Events: SystemClock{},
+	}
+}
+
+// NewSeededSource derives the tweets from seed, their time starts at SeedEpoch
+// and moves one millisecond forward on every reading
+// so the same seed yields a byte identical tweet sequence
+// event envelopes still carry the wall clock emit time
+func NewSeededSource(seed int64) *Source {
+	r := NewRandom(seed)
+	return &Source{
+		Rand:   r,
+		IDs:    SeededIDGenerator{Rand: r},
+		Clock:  NewStepClock(SeedEpoch, time.Millisecond),
+		Events: SystemClock{},
+	}
+}
@@ -1,17 +1,21 @@
 package utils
 
 import (
-	"math/rand"
 	"strings"
 
 	"github.com/Udehlee/tweet-stream/models"
 	"github.com/google/uuid"
 )
 
-// Airline returns any of the User listed
-func GenerateUser() string {
-	Users := []string{"Baba-K", "Uncle-lee", "Obekwu", "Naija"}
-	index := rand.Intn(len(Users))
+// GenerateUser returns any of the User listed
+func GenerateUser(r *Random) string {
+	Users := []string{
+		"Baba-K", "Uncle-lee", "Obekwu", "Naija",
+		"Ada-Eze", "Tunde", "Chioma", "Emeka",
+		"Aunty-Bisi", "Kelechi", "Ngozi", "Sade",
+		"Oga-Femi", "Zainab", "Ifeanyi", "Amaka",
+	}
+	index := r.Intn(len(Users))
 	return Users[index]
 }
 